@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"player-scraper/internal/core"
+)
+
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "table", "Report format (csv, json or table)")
+	output := fs.String("output", "", "Write the report to a file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s diff [options] <old export|rosters dir> <new export|rosters dir>:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	before, err := core.LoadSnapshot(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", fs.Arg(0), err)
+	}
+	after, err := core.LoadSnapshot(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", fs.Arg(1), err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create report file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := core.WriteDiff(w, core.DiffRosters(before, after), *format); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
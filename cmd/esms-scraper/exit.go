@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"player-scraper/internal/core"
+
+	"github.com/fatih/color"
+)
+
+// Process exit codes, 1 is left for unexpected errors and 2 for usage errors.
+const (
+	exitSuccess        = 0
+	exitPartialFailure = 3
+	exitTeamsPage      = 4
+	exitExport         = 5
+	exitStopped        = 6
+	exitCancelled      = 130
+)
+
+// exitWith prints the message and terminates the process with the code.
+func exitWith(code int, format string, args ...interface{}) {
+	color.Red(format, args...)
+	os.Exit(code)
+}
+
+// scrapeExitCode checks the run against the failure thresholds. A club that
+// was not loaded counts as a failure, whether its roster failed or the run
+// stopped before it was attempted.
+func scrapeExitCode(manifest *core.Manifest, maxFailures int, minClubs int) int {
+	failures := manifest.Clubs - manifest.Loaded
+	code := exitSuccess
+
+	if maxFailures >= 0 && failures > maxFailures {
+		color.Red("Scrape incomplete\t ... %d of %d clubs failed (max %d)", failures, manifest.Clubs, maxFailures)
+		code = exitPartialFailure
+	}
+
+	if manifest.Loaded < minClubs {
+		color.Red("Scrape incomplete\t ... %d clubs loaded (min %d)", manifest.Loaded, minClubs)
+		code = exitPartialFailure
+	}
+
+	return code
+}
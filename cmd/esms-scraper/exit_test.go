@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"testing"
+
+	"player-scraper/internal/core"
+)
+
+func TestScrapeExitCodeOneFailedRoster(t *testing.T) {
+	rosters := []*core.RosterFile{
+		{Code: "ARS", Players: []*core.Player{{Name: "A_One"}}},
+		{Code: "CHE"},
+	}
+	errs := []error{&core.ScrapeError{Code: "CHE", Kind: core.FileRoster, Err: errors.New("404 Not Found")}}
+	manifest := core.NewManifest(rosters, errs, core.ScraperOptions{}, core.ScrapeMeta{}, "")
+
+	// the run is checked with the defaults of the flags
+	maxFailures, err := strconv.Atoi(flag.Lookup("max-failures").DefValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minClubs, err := strconv.Atoi(flag.Lookup("min-clubs").DefValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := scrapeExitCode(manifest, maxFailures, minClubs); code != exitPartialFailure {
+		t.Errorf("got exit code %d, want %d", code, exitPartialFailure)
+	}
+	if code := scrapeExitCode(manifest, 1, minClubs); code != exitSuccess {
+		t.Errorf("got exit code %d with -max-failures 1, want %d", code, exitSuccess)
+	}
+}
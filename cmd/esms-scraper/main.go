@@ -0,0 +1,483 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"player-scraper/internal/core"
+	_ "player-scraper/internal/ffo"
+	"player-scraper/internal/generic"
+	_ "player-scraper/internal/ssl"
+	"player-scraper/internal/ui"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/skratchdot/open-golang/open"
+)
+
+var (
+	version            = "dev"
+	defaultGame        = ""
+	flagGame           = flag.String("game", defaultGame, fmt.Sprintf("Game to scrape (%s)", strings.Join(core.GameNames(), ", ")))
+	flagTeamsUrl       = flag.String("teams-url", "", "URL to scrape for team information on the game website (defaults to the game's clubs page)")
+	flagDownloadFiles  = flag.Bool("download-files", false, "Download the latest rosters from the game website")
+	flagRostersDir     = flag.String("rosters-dir", ".", "Target directory for downloading or sourcing local rosters")
+	flagOutputDir      = flag.String("output-dir", ".", "Output directory for exported files")
+	flagMaxParallel    = flag.Int("max-concurrent", 5, "Number of concurrent requests when loading roster files")
+	flagStopOnError    = flag.Bool("stop-on-error", false, "Stop all requests on first error")
+	flagExcelExport    = flag.Bool("excel-export", true, "Use Excel-compatible formulas instead of raw values for calculated fields")
+	flagFormat         = flag.String("format", "csv", "Export format (csv, json, ndjson or xlsx)")
+	flagDatabase       = flag.String("db", "", "SQLite database file to append each scrape run to")
+	flagGamesConfig    = flag.String("games-config", "", "YAML or JSON file with additional game definitions")
+	flagLoader         = flag.String("loader", "http", "Roster loader to use (http or colly)")
+	flagCacheDir       = flag.String("cache-dir", defaultCacheDir(), "Directory for caching downloaded roster files, set to empty to disable")
+	flagMaxAttempts    = flag.Int("max-attempts", core.DefaultRetryPolicy.MaxAttempts, "Maximum number of attempts for each roster download")
+	flagRetryDelay     = flag.Duration("retry-delay", core.DefaultRetryPolicy.BaseDelay, "Delay before the first retry, doubled on every further attempt")
+	flagRetryMaxDelay  = flag.Duration("retry-max-delay", core.DefaultRetryPolicy.MaxDelay, "Maximum delay between retries")
+	flagUserAgent      = flag.String("user-agent", core.DefaultUserAgent+"/"+version, "User-Agent header sent with every request")
+	flagRateLimit      = flag.Float64("rate-limit", 5, "Maximum number of requests per second to each host, 0 for no limit")
+	flagRobots         = flag.Bool("robots", false, "Skip the files disallowed by the website's robots.txt")
+	flagProxy          = flag.String("proxy", "", "Proxy URL for every request, e.g. http://proxy.example.com:8080 (defaults to the HTTP_PROXY and HTTPS_PROXY environment variables)")
+	flagCaCert         = flag.String("ca-cert", "", "PEM file of additional certificate authorities to trust, e.g. of a proxy intercepting TLS")
+	flagInsecure       = flag.Bool("insecure-skip-verify", false, "Accept any TLS certificate, only for testing")
+	flagRequestTimeout = flag.Duration("request-timeout", 30*time.Second, "Maximum time for each HTTP request, 0 for no limit")
+	flagTotalTimeout   = flag.Duration("total-timeout", 0, "Maximum time for loading the clubs and rosters, 0 for no limit")
+	flagErrorsFile     = flag.String("errors-file", "", "Write the scrape errors to a JSON file, e.g. errors.json")
+	flagMaxFailures    = flag.Int("max-failures", 0, "Maximum number of clubs that may fail before the run exits with an error, -1 for no limit")
+	flagMinClubs       = flag.Int("min-clubs", 0, "Minimum number of clubs that must load for the run to succeed")
+	flagColumnsConfig  = flag.String("columns-config", "", "YAML or JSON file with computed column definitions")
+	flagExportColumns  = flag.String("export-columns", "", "Columns to export and their order, comma separated e.g. \"Team,Name,Age,St,Tk,Ps,Sh\" (default all)")
+	flagSort           = flag.String("sort", "", "Columns to sort the export by, comma separated, prefix with - for descending e.g. \"League,-Sh\"")
+	flagNoAcademy      = flag.Bool("exclude-academy", false, "Leave academy and youth players out of the export")
+	flagSquads         = flag.String("squads", "", "Only export the players of these squads (Senior, Academy, Youth), comma separated")
+	flagLeagues        = flag.String("leagues", "", "Only export the clubs of these leagues, comma separated")
+	flagRecord         = flag.String("record", "", "Directory to record every HTTP request and response of the scrape to")
+	flagReplay         = flag.String("replay", "", "Directory of a recorded scrape to replay instead of accessing the website")
+	flagCiMode         = flag.Bool("ci", false, "Run in CI mode and disable prompts")
+	flagColumns        stringList
+)
+
+func init() {
+	flag.Var(&flagColumns, "column", "Computed column to add to the export, e.g. \"GlsPer90 = Gls / Min * 90\" (repeatable)")
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "player-scraper")
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// loadComputedColumns reads the computed columns of the config file followed
+// by the ones given on the command line.
+func loadComputedColumns(configPath string, defs []string) []*core.ComputedColumn {
+	cols := []*core.ComputedColumn{}
+	if configPath != "" {
+		var err error
+		if cols, err = core.LoadComputedColumns(configPath); err != nil {
+			log.Fatalf("Failed to load computed columns: %v", err)
+		}
+	}
+	for _, def := range defs {
+		column, err := core.ParseComputedColumn(def)
+		if err != nil {
+			log.Fatalf("Invalid column: %v", err)
+		}
+		cols = append(cols, column)
+	}
+	if err := core.CheckComputedColumns(cols); err != nil {
+		log.Fatalf("Invalid column: %v", err)
+	}
+	return cols
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(out, "  %s [-game <game>] [options]\n", os.Args[0])
+	fmt.Fprintf(out, "  %s <game> [options]\n", os.Args[0])
+	fmt.Fprintf(out, "  %s diff [options] <old> <new>\n", os.Args[0])
+	fmt.Fprintf(out, "  %s search [options] [export|rosters dir]\n\n", os.Args[0])
+	fmt.Fprintf(out, "Games: %s\n\nOptions:\n", strings.Join(core.GameNames(), ", "))
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "diff" {
+		runDiff(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "search" {
+		runSearch(args[1:])
+		return
+	}
+
+	// the positional game may be defined in -games-config, so it's only
+	// checked once the flags are parsed and the games registered
+	positionalGame := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positionalGame = args[0]
+		*flagGame = positionalGame
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args)
+
+	if *flagGamesConfig != "" {
+		if err := generic.RegisterGames(*flagGamesConfig); err != nil {
+			log.Fatalf("Failed to load game definitions: %v", err)
+		}
+	}
+
+	if positionalGame != "" && !slices.Contains(core.GameNames(), strings.ToLower(positionalGame)) {
+		fmt.Fprintf(flag.CommandLine.Output(), "Unknown command: %s\n", positionalGame)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "Unknown command: %s\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *flagGame == "" {
+		fmt.Fprintln(flag.CommandLine.Output(), "No game selected.")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	game, err := core.GetGame(*flagGame)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *flagTeamsUrl == "" {
+		*flagTeamsUrl = game.TeamsUrl
+	}
+
+	parsedUrl, err := url.Parse(*flagTeamsUrl)
+	if err != nil {
+		log.Fatalf("Failed to parse URL: %v", err)
+	}
+
+	format, err := core.ParseExportFormat(*flagFormat)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	if *flagRecord != "" && *flagReplay != "" {
+		log.Fatal("-record and -replay can't be used together")
+	}
+
+	if !slices.Contains([]string{"http", "colly"}, *flagLoader) {
+		log.Fatalf("Unknown loader: %s", *flagLoader)
+	}
+
+	squads, err := core.ParseSquads(splitList(*flagSquads))
+	if err != nil {
+		log.Fatalf("Invalid squads: %v", err)
+	}
+
+	computedColumns := loadComputedColumns(*flagColumnsConfig, flagColumns)
+
+	opts := core.ScraperOptions{
+		LocalOnly:      false,
+		DownloadFiles:  *flagDownloadFiles,
+		RosterDir:      *flagRostersDir,
+		OutputDir:      *flagOutputDir,
+		ExcelExport:    *flagExcelExport,
+		Format:         format,
+		Columns:        splitList(*flagExportColumns),
+		Sort:           core.ParseSortKeys(*flagSort),
+		ExcludeAcademy: *flagNoAcademy,
+		Leagues:        splitList(*flagLeagues),
+		Squads:         squads,
+	}
+
+	appName := fmt.Sprintf("%s Player Scraper v%s", game.Title, version)
+	ciMode := true
+	if flagCiMode == nil || !*flagCiMode {
+		ciMode = false
+		var cancelled bool
+		opts, cancelled = ui.Run(appName)
+		if cancelled {
+			os.Exit(0)
+		}
+	}
+
+	if len(opts.Columns) > 0 && !opts.Format.SelectsColumns() {
+		log.Fatalf("Invalid columns: %s exports always contain every column, choose csv or xlsx to select columns", opts.Format)
+	}
+
+	fmt.Print(fmt.Sprintf("\n%s\n", ui.StyleTitle(appName)))
+
+	// the team provider and roster loader share one transport so the rate
+	// limit covers the whole scrape, a replay serves the recorded responses in
+	// place of the network and the cache
+	baseTransport, err := core.NewHttpTransport(core.TransportOptions{
+		Proxy:              *flagProxy,
+		CACertFile:         *flagCaCert,
+		InsecureSkipVerify: *flagInsecure,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure HTTP transport: %v", err)
+	}
+	if *flagInsecure {
+		color.Yellow("TLS certificates are not verified (-insecure-skip-verify)")
+	}
+
+	var transport http.RoundTripper = &core.PoliteTransport{
+		UserAgent:         *flagUserAgent,
+		RequestsPerSecond: *flagRateLimit,
+		Burst:             *flagMaxParallel,
+		RespectRobots:     *flagRobots,
+		Transport:         baseTransport,
+	}
+	if *flagReplay != "" {
+		replay, err := core.NewHttpReplay(*flagReplay)
+		if err != nil {
+			log.Fatalf("Failed to open recording: %v", err)
+		}
+		transport = replay
+	}
+
+	var cache *core.HttpCache
+	if *flagCacheDir != "" && !opts.LocalOnly && *flagReplay == "" {
+		cache, err = core.NewHttpCache(*flagCacheDir, transport)
+		if err != nil {
+			color.Yellow("Failed to create cache directory, caching disabled: %v", err)
+		} else {
+			transport = cache
+		}
+	}
+
+	if *flagRecord != "" {
+		recorder, err := core.NewHttpRecorder(*flagRecord, transport)
+		if err != nil {
+			log.Fatalf("Failed to create recording directory: %v", err)
+		}
+		transport = recorder
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	scrapeCtx := signalCtx
+	if *flagTotalTimeout > 0 {
+		var cancelScrape context.CancelFunc
+		scrapeCtx, cancelScrape = context.WithTimeout(signalCtx, *flagTotalTimeout)
+		defer cancelScrape()
+	}
+
+	fmt.Print("Loading clubs")
+	provider := game.NewTeamProvider(parsedUrl.String(), core.ProviderOptions{
+		Transport:      transport,
+		Context:        scrapeCtx,
+		RequestTimeout: *flagRequestTimeout,
+	})
+	rosters, err := provider.Load()
+	if signalCtx.Err() != nil {
+		exitWith(exitCancelled, "\nScrape cancelled")
+	}
+	if scrapeCtx.Err() != nil {
+		exitWith(exitTeamsPage, "\nTimed out loading clubs after %v", *flagTotalTimeout)
+	}
+	if err != nil {
+		exitWith(exitTeamsPage, "\nFailed to load clubs: %v", err)
+	}
+	if len(rosters) == 0 {
+		exitWith(exitTeamsPage, "\nNo clubs found on %s", parsedUrl)
+	}
+	fmt.Println("\t\t ... done!")
+
+	tracker := &progress.Tracker{
+		DeferStart:         false,
+		RemoveOnCompletion: false,
+		Message:            "Scraping rosters",
+		Total:              int64(len(rosters)),
+		Units:              progress.UnitsDefault,
+	}
+	tracker.SetValue(0)
+
+	ctx, cancel := context.WithCancel(scrapeCtx)
+	remoteUrl := fmt.Sprintf("%s://%s", parsedUrl.Scheme, parsedUrl.Host)
+	if opts.LocalOnly {
+		remoteUrl = ""
+	}
+	onLoaded := func(r *core.RosterFile) {
+		tracker.Increment(1)
+	}
+	onError := func(e error) {
+		if !opts.LocalOnly && *flagStopOnError {
+			cancel()
+		} else {
+			tracker.IncrementWithError(1)
+		}
+	}
+
+	retryPolicy := core.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = max(*flagMaxAttempts, 1)
+	retryPolicy.BaseDelay = *flagRetryDelay
+	retryPolicy.MaxDelay = *flagRetryMaxDelay
+
+	var loader core.RosterLoader
+	switch *flagLoader {
+	case "http":
+		loader = &core.FileRosterLoader{
+			Dir:            opts.RosterDir,
+			RemoteUrl:      remoteUrl,
+			DownloadFiles:  opts.DownloadFiles,
+			MaxConcurrent:  *flagMaxParallel,
+			Transport:      transport,
+			RequestTimeout: *flagRequestTimeout,
+			RetryPolicy:    &retryPolicy,
+			OnLoaded:       onLoaded,
+			OnError:        onError,
+		}
+	case "colly":
+		loader = &core.CollyRosterLoader{
+			Dir:            opts.RosterDir,
+			RemoteUrl:      remoteUrl,
+			DownloadFiles:  opts.DownloadFiles,
+			MaxConcurrent:  *flagMaxParallel,
+			Transport:      transport,
+			RequestTimeout: *flagRequestTimeout,
+			RetryPolicy:    &retryPolicy,
+			OnLoaded:       onLoaded,
+			OnError:        onError,
+		}
+	default:
+		log.Fatalf("Unknown loader: %s", *flagLoader)
+	}
+	// instantiate a Progress Writer and set up the options
+	pw := progress.NewWriter()
+	pw.AppendTracker(tracker)
+	pw.SetAutoStop(false)
+	pw.SetMessageLength(24)
+	pw.SetNumTrackersExpected(1)
+	pw.SetTrackerLength(len(rosters))
+	pw.SetTrackerPosition(progress.PositionRight)
+	pw.SetSortBy(progress.SortByPercentDsc)
+	pw.SetStyle(progress.StyleDefault)
+	pw.SetUpdateFrequency(time.Millisecond * 100)
+	pw.Style().Colors = progress.StyleColorsExample
+	pw.Style().Options.PercentFormat = "%4.1f%%"
+	// render async
+	go pw.Render()
+
+	result := loader.Load(rosters, ctx)
+	if result.Cancelled {
+		tracker.MarkAsErrored()
+	} else {
+		tracker.MarkAsDone()
+	}
+
+	pw.Stop()
+
+	retried := []string{}
+	for _, r := range rosters {
+		if r.Attempts > 1 {
+			retried = append(retried, fmt.Sprintf("%s (%d attempts)", r.Code, r.Attempts))
+		}
+	}
+	if len(retried) > 0 {
+		color.Yellow("Retried\t\t\t ... %s", strings.Join(retried, ", "))
+	}
+
+	if cache != nil && cache.Hits() > 0 {
+		color.Blue("Cache hits\t\t ... %d of %d files", cache.Hits(), cache.Hits()+cache.Misses())
+	}
+
+	allErrors := core.CollectErrors(rosters, result.Errors)
+	errMessages := []string{}
+	for _, e := range allErrors {
+		errMessages = append(errMessages, e.Error())
+	}
+	meta := core.ScrapeMeta{
+		Game:      game.Title,
+		SourceUrl: parsedUrl.String(),
+		Timestamp: time.Now(),
+		Version:   version,
+		Errors:    errMessages,
+	}
+	fileNamePrefix := fmt.Sprintf("%s_players_", game.Name)
+	exportFile, err := core.Export(rosters, opts.Format, core.ExportOptions{
+		OutputDir:        opts.OutputDir,
+		FileNamePrefix:   fileNamePrefix,
+		Title:            fmt.Sprintf("%s Player List", game.Title),
+		UseExcelFormulas: opts.ExcelExport,
+		ComputedColumns:  computedColumns,
+		Columns:          opts.Columns,
+		Sort:             opts.Sort,
+		ExcludeAcademy:   opts.ExcludeAcademy,
+		Leagues:          opts.Leagues,
+		Squads:           opts.Squads,
+		Meta:             meta,
+	})
+	if err != nil {
+		exitWith(exitExport, "Failed to create output file: %v", err)
+	}
+
+	manifest := core.NewManifest(rosters, allErrors, opts, meta, exportFile)
+	manifest.Game = game.Name
+	if _, err := core.WriteManifest(manifest, opts.OutputDir, fileNamePrefix); err != nil {
+		color.Yellow("Failed to write manifest file: %v", err)
+	}
+
+	if *flagDatabase != "" {
+		if _, err = core.ExportToSqlite(rosters, *flagDatabase, meta); err != nil {
+			exitWith(exitExport, "Failed to write to database: %v", err)
+		}
+	}
+
+	core.PrintErrorSummary(allErrors)
+	if *flagErrorsFile != "" {
+		if err := core.WriteErrorsFile(*flagErrorsFile, allErrors); err != nil {
+			color.Yellow("Failed to write errors file: %v", err)
+		}
+	}
+
+	exitCode := scrapeExitCode(manifest, *flagMaxFailures, *flagMinClubs)
+	if signalCtx.Err() != nil {
+		color.Red("Scrape cancelled\t ... %d of %d clubs loaded", manifest.Loaded, manifest.Clubs)
+		exitCode = exitCancelled
+	}
+	if signalCtx.Err() == nil && scrapeCtx.Err() != nil {
+		color.Red("Scrape timed out\t ... %d of %d clubs loaded after %v", manifest.Loaded, manifest.Clubs, *flagTotalTimeout)
+		exitCode = exitStopped
+	}
+	if scrapeCtx.Err() == nil && ctx.Err() != nil {
+		color.Red("Scrape stopped\t\t ... %d of %d clubs loaded before the first error", manifest.Loaded, manifest.Clubs)
+		exitCode = exitStopped
+	}
+
+	if !ciMode {
+		fmt.Println("Press enter key to close ...")
+		fmt.Scanln()
+		open.Start(opts.OutputDir)
+	}
+
+	os.Exit(exitCode)
+}
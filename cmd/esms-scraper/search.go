@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"player-scraper/internal/core"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var searchColumns = []string{"Team", "League", "Name", "Age", "Nat", "Prs", "St", "Tk", "Ps", "Sh", "Ag", "Gam", "Min", "Gls", "Ass"}
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runSearch(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	var where, minSkills, columns stringList
+	fs.Var(&where, "where", "Filter on any column, e.g. \"Sh>=14\", \"Prs=L,R\" or \"Name~smith\" (repeatable)")
+	fs.Var(&columns, "column", "Computed column to filter or sort on, e.g. \"GlsPer90 = Gls / Min * 90\" (repeatable)")
+	columnsConfig := fs.String("columns-config", "", "YAML or JSON file with computed column definitions")
+	fs.Var(&minSkills, "min-skill", "Minimum value of a skill, e.g. \"Sh=14\" (repeatable)")
+	minAge := fs.Int("min-age", 0, "Minimum age")
+	maxAge := fs.Int("max-age", 0, "Maximum age")
+	nat := fs.String("nat", "", "Nationalities, comma separated")
+	league := fs.String("league", "", "Leagues, comma separated")
+	side := fs.String("side", "", "Side from the name suffix (L, R or C)")
+	sortBy := fs.String("sort", "", "Columns to sort by, comma separated, prefix with - for descending e.g. \"-Sh,Age\"")
+	limit := fs.Int("limit", 0, "Maximum number of players to list")
+	format := fs.String("format", "table", "Output format (table, csv or json)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s search [options] [export|rosters dir]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := "."
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	query := core.PlayerQuery{Sort: core.ParseSortKeys(*sortBy), Limit: *limit, Computed: loadComputedColumns(*columnsConfig, columns)}
+	for _, expr := range where {
+		c, err := core.ParseCondition(expr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		query.Conditions = append(query.Conditions, c)
+	}
+	for _, expr := range minSkills {
+		skill, value, ok := strings.Cut(expr, "=")
+		if !ok {
+			log.Fatalf("invalid skill %q, expected e.g. Sh=14", expr)
+		}
+		query.Conditions = append(query.Conditions, core.Condition{Column: skill, Op: ">=", Value: value})
+	}
+	if *minAge > 0 {
+		query.Conditions = append(query.Conditions, core.Condition{Column: "Age", Op: ">=", Value: strconv.Itoa(*minAge)})
+	}
+	if *maxAge > 0 {
+		query.Conditions = append(query.Conditions, core.Condition{Column: "Age", Op: "<=", Value: strconv.Itoa(*maxAge)})
+	}
+	if *nat != "" {
+		query.Conditions = append(query.Conditions, core.Condition{Column: "Nat", Op: "=", Value: *nat})
+	}
+	if *league != "" {
+		query.Conditions = append(query.Conditions, core.Condition{Column: "League", Op: "=", Value: *league})
+	}
+	if *side != "" {
+		query.Conditions = append(query.Conditions, core.Condition{Column: "Side", Op: "~", Value: *side})
+	}
+
+	rosters, err := loadSearchRosters(path)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", path, err)
+	}
+
+	cols := slices.Clone(searchColumns)
+	for _, col := range query.Columns() {
+		if !slices.ContainsFunc(cols, func(c string) bool { return strings.EqualFold(c, col) }) {
+			cols = append(cols, col)
+		}
+	}
+
+	players, err := core.SearchPlayers(rosters, query)
+	if err != nil {
+		log.Fatalf("Invalid query: %v, a computed column needs its definition (-column or -columns-config)", err)
+	}
+	if err := core.WritePlayers(os.Stdout, players, cols, *format); err != nil {
+		log.Fatalf("Failed to write players: %v", err)
+	}
+}
+
+// loadSearchRosters loads the rosters of a directory with the file roster
+// loader in local mode, or reads back a previous export.
+func loadSearchRosters(path string) ([]*core.RosterFile, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return core.LoadSnapshot(path)
+	}
+
+	rosters, err := core.ListLocalRosters(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := &core.FileRosterLoader{
+		Dir: path,
+		OnError: func(e error) {
+			fmt.Fprintf(os.Stderr, "Skipping roster: %v\n", e)
+		},
+	}
+	loader.Load(rosters, context.Background())
+	return rosters, nil
+}
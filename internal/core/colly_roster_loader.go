@@ -0,0 +1,264 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// CollyRosterLoader loads rosters with an async colly collector. It supports
+// the same options as FileRosterLoader.
+type CollyRosterLoader struct {
+	RemoteUrl     string
+	DownloadFiles bool
+	Dir           string
+	MaxConcurrent int
+	Transport     http.RoundTripper
+	// RequestTimeout limits each download, no limit when zero
+	RequestTimeout time.Duration
+	RetryPolicy    *RetryPolicy
+	OnLoaded       func(*RosterFile)
+	OnError        func(error)
+}
+
+const (
+	collyKindRoster  = string(FileRoster)
+	collyKindAcademy = string(FileAcademy)
+	collyKindInfo    = string(FileInfo)
+)
+
+type collyRosterState struct {
+	roster  *RosterFile
+	content []byte
+	pending int
+	done    bool
+	start   time.Time
+}
+
+func (l *CollyRosterLoader) retryPolicy() RetryPolicy {
+	if l.RetryPolicy != nil {
+		return *l.RetryPolicy
+	}
+	return DefaultRetryPolicy
+}
+
+func (l *CollyRosterLoader) loadLocal(rosters []*RosterFile, ctx context.Context, result *LoadResult) {
+	for _, roster := range rosters {
+		if ctx.Err() != nil {
+			result.add(rosterOutcome{roster: roster, skipped: true})
+			continue
+		}
+
+		start := time.Now()
+		err := loadLocalRoster(l.Dir, roster)
+		roster.Duration = time.Since(start)
+		if err != nil {
+			err = newScrapeError(roster, FileRoster, localRosterPath(l.Dir, roster), err)
+			result.add(rosterOutcome{roster: roster, err: err})
+			if l.OnError != nil {
+				l.OnError(err)
+			}
+			continue
+		}
+
+		loadLocalInfo(l.Dir, roster)
+		result.add(rosterOutcome{roster: roster})
+		if l.OnLoaded != nil {
+			l.OnLoaded(roster)
+		}
+	}
+}
+
+func (l *CollyRosterLoader) Load(rosters []*RosterFile, ctx context.Context) *LoadResult {
+	result := newLoadResult()
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+		result.Cancelled = ctx.Err() != nil
+	}()
+
+	if l.RemoteUrl == "" {
+		l.loadLocal(rosters, ctx, result)
+		return result
+	}
+
+	collector := colly.NewCollector(
+		colly.Async(true),
+	)
+
+	// colly requests have no context, the transport aborts them instead
+	collector.WithTransport(&ContextTransport{Context: ctx, Timeout: l.RequestTimeout, Transport: l.Transport})
+
+	collector.Limit(&colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: int(math.Max(1, float64(l.MaxConcurrent))),
+	})
+
+	// callbacks and the result are serialised so OnLoaded/OnError never run
+	// concurrently
+	var mu sync.Mutex
+	states := make([]*collyRosterState, len(rosters))
+	for i, r := range rosters {
+		states[i] = &collyRosterState{roster: r, start: time.Now()}
+	}
+
+	finish := func(st *collyRosterState, err error) {
+		st.done = true
+		st.roster.Duration = time.Since(st.start)
+		if err == nil && l.DownloadFiles {
+			err = saveRosterFile(l.Dir, st.roster, st.content)
+		}
+
+		if err != nil {
+			location, _ := resolveFileUrl(l.RemoteUrl, st.roster.FileLocation)
+			err = newScrapeError(st.roster, FileRoster, location, err)
+			result.add(rosterOutcome{roster: st.roster, err: err})
+			if l.OnError != nil {
+				l.OnError(err)
+			}
+			return
+		}
+
+		loadLocalInfo(l.Dir, st.roster)
+		result.add(rosterOutcome{roster: st.roster})
+		if l.OnLoaded != nil {
+			l.OnLoaded(st.roster)
+		}
+	}
+
+	request := func(index int, kind string, filePath string) error {
+		fileUrl, err := resolveFileUrl(l.RemoteUrl, filePath)
+		if err != nil {
+			return err
+		}
+
+		reqCtx := colly.NewContext()
+		reqCtx.Put("index", strconv.Itoa(index))
+		reqCtx.Put("kind", kind)
+		return collector.Request("GET", fileUrl, nil, reqCtx, nil)
+	}
+
+	stateOf := func(r *colly.Request) (int, *collyRosterState, string) {
+		index, _ := strconv.Atoi(r.Ctx.Get("index"))
+		return index, states[index], r.Ctx.Get("kind")
+	}
+
+	collector.OnRequest(func(r *colly.Request) {
+		// If context is cancelled, stop the request
+		if ctx.Err() != nil {
+			r.Abort()
+		}
+	})
+
+	collector.OnResponse(func(r *colly.Response) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		index, st, kind := stateOf(r.Request)
+		if st.done {
+			return
+		}
+
+		switch kind {
+		case collyKindRoster:
+			if err := parseRosterContent(st.roster, r.Body); err != nil {
+				finish(st, err)
+				return
+			}
+			st.content = r.Body
+
+			// scrape academy and info once the roster is parsed
+			if st.roster.AcademyFileLocation != "" && request(index, collyKindAcademy, st.roster.AcademyFileLocation) == nil {
+				st.pending++
+			}
+			if st.roster.InfoFileLocation != "" && request(index, collyKindInfo, st.roster.InfoFileLocation) == nil {
+				st.pending++
+			}
+		case collyKindAcademy:
+			st.pending--
+			if err := parseAcademyContent(st.roster, r.Body); err != nil {
+				st.roster.Errors = append(st.roster.Errors, newScrapeError(st.roster, FileAcademy, r.Request.URL.String(), err))
+			}
+		case collyKindInfo:
+			st.pending--
+			if err := parseInfoContent(st.roster, r.Body); err != nil {
+				st.roster.Errors = append(st.roster.Errors, newScrapeError(st.roster, FileInfo, r.Request.URL.String(), err))
+			}
+		}
+
+		if st.pending == 0 {
+			finish(st, nil)
+		}
+	})
+
+	policy := l.retryPolicy()
+	collector.OnError(func(r *colly.Response, e error) {
+		mu.Lock()
+
+		// a roster interrupted by the cancellation is skipped
+		_, st, kind := stateOf(r.Request)
+		if st.done || ctx.Err() != nil {
+			mu.Unlock()
+			return
+		}
+
+		err := fmt.Errorf("file download failed: %s - %w", r.Request.URL, e)
+		if r.StatusCode != 0 {
+			err = newHttpStatusError(r.Request.URL.String(), r.StatusCode, *r.Headers)
+		}
+
+		if kind != collyKindRoster {
+			// academy and info files are optional
+			st.roster.Errors = append(st.roster.Errors, newScrapeError(st.roster, FileKind(kind), r.Request.URL.String(), err))
+			st.pending--
+			if st.pending == 0 {
+				finish(st, nil)
+			}
+			mu.Unlock()
+			return
+		}
+
+		if !policy.ShouldRetry(err, st.roster.Attempts) {
+			finish(st, err)
+			mu.Unlock()
+			return
+		}
+
+		delay := policy.Delay(err, st.roster.Attempts)
+		st.roster.Attempts++
+		mu.Unlock()
+
+		// wait before the next attempt unless cancelled
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+			r.Request.Retry()
+		}
+	})
+
+	for i, r := range rosters {
+		r.Attempts = 1
+		if err := request(i, collyKindRoster, r.FileLocation); err != nil {
+			mu.Lock()
+			finish(states[i], err)
+			mu.Unlock()
+		}
+	}
+
+	collector.Wait()
+
+	// the rosters left are the ones interrupted by the cancellation
+	for _, st := range states {
+		if !st.done {
+			st.roster.Columns, st.roster.Players, st.roster.Info = nil, nil, nil
+			result.add(rosterOutcome{roster: st.roster, skipped: true})
+		}
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ComputedColumn is an export column calculated from other columns of the
+// same player.
+type ComputedColumn struct {
+	Name string
+	Expr *Expression
+	// After places the column after the named column instead of at the end
+	After string
+}
+
+// defaultComputedColumns are the <stat>/min columns every export has.
+var defaultComputedColumns = func() []*ComputedColumn {
+	cols := []*ComputedColumn{}
+	for _, stat := range []string{"Sav", "Ktk", "Kps", "Gls"} {
+		expr, _ := ParseExpression(stat + " / Min")
+		cols = append(cols, &ComputedColumn{Name: stat + "/min", Expr: expr, After: stat})
+	}
+	return cols
+}()
+
+func NewComputedColumn(name string, expr string) (*ComputedColumn, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("computed column %q has no name", expr)
+	}
+
+	parsed, err := ParseExpression(expr)
+	if err != nil {
+		return nil, fmt.Errorf("computed column %s: %w", name, err)
+	}
+	return &ComputedColumn{Name: name, Expr: parsed}, nil
+}
+
+// ParseComputedColumn parses a column definition such as
+// "GlsPer90 = Gls / Min * 90".
+func ParseComputedColumn(def string) (*ComputedColumn, error) {
+	name, expr, ok := strings.Cut(def, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid computed column %q, expected e.g. \"GlsPer90 = Gls / Min * 90\"", def)
+	}
+	return NewComputedColumn(name, expr)
+}
+
+type columnsFile struct {
+	Columns []struct {
+		Name string `yaml:"name"`
+		Expr string `yaml:"expr"`
+	} `yaml:"columns"`
+}
+
+// LoadComputedColumns reads the column definitions of a YAML or JSON file.
+func LoadComputedColumns(path string) ([]*ComputedColumn, error) {
+	file := columnsFile{}
+	if err := ReadConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	cols := []*ComputedColumn{}
+	for _, c := range file.Columns {
+		col, err := NewComputedColumn(c.Name, c.Expr)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// CheckComputedColumns returns an error if a column's expression refers to
+// a computed column, its values are calculated from the roster columns only.
+func CheckComputedColumns(cols []*ComputedColumn) error {
+	all := computedColumns(cols)
+	for _, c := range cols {
+		for _, col := range c.Expr.Columns() {
+			for _, other := range all {
+				if strings.EqualFold(col, other.Name) || exportColumnName(col) == other.Name {
+					return fmt.Errorf("computed column %s: can't refer to computed column %s", c.Name, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// exportColumnName maps a column used in an expression onto the export
+// header it reads from.
+func exportColumnName(col string) string {
+	switch strings.ToLower(col) {
+	case "wage":
+		return "Wage"
+	case "value", "mkt value":
+		return "Mkt Value"
+	case "contract":
+		return "Contract"
+	}
+	return normalizeColumnName(col)
+}
+
+// missingColumns returns the columns of the expression that aren't among
+// the headers.
+func (c *ComputedColumn) missingColumns(headers []string) []string {
+	missing := []string{}
+	for _, col := range c.Expr.Columns() {
+		if !slices.Contains(headers, exportColumnName(col)) {
+			missing = append(missing, col)
+		}
+	}
+	return missing
+}
+
+// available reports whether the roster has every column the expression
+// needs.
+func (c *ComputedColumn) available(r *RosterFile) bool {
+	for _, col := range c.Expr.Columns() {
+		name := exportColumnName(col)
+		if name == "Wage" || name == "Mkt Value" {
+			if r.Info == nil {
+				return false
+			}
+		} else if name == "Contract" {
+			if r.Info == nil || r.Info.ContractColumn == "" {
+				return false
+			}
+		} else if !slices.Contains(r.Columns, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Eval calculates the column for a player, dividing by zero gives 0 like
+// the IFERROR of the spreadsheet formula.
+func (c *ComputedColumn) Eval(p *Player) (float64, error) {
+	val, err := c.Expr.Eval(func(col string) (float64, error) {
+		switch name := exportColumnName(col); name {
+		case "Wage":
+			return p.Wage, nil
+		case "Mkt Value":
+			return p.Value, nil
+		case "Contract":
+			return float64(p.Contract), nil
+		default:
+			raw, ok := p.Get(name)
+			if !ok {
+				return 0, fmt.Errorf("unknown column %s", col)
+			}
+			num, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return 0, fmt.Errorf("column %s is not numeric", col)
+			}
+			return num, nil
+		}
+	})
+	if err == errDivideByZero {
+		return 0, nil
+	}
+	return val, err
+}
+
+// formatComputedValue formats a computed value for a text export.
+func formatComputedValue(val float64) string {
+	if val == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%f", val)
+}
+
+// computedColumns returns the default columns followed by the given ones.
+func computedColumns(cols []*ComputedColumn) []*ComputedColumn {
+	return append(slices.Clone(defaultComputedColumns), cols...)
+}
+
+// computedValues calculates the given columns for a player of the roster,
+// leaving out those the roster lacks the columns for.
+func computedValues(r *RosterFile, p *Player, cols []*ComputedColumn) map[string]float64 {
+	if len(cols) == 0 {
+		return nil
+	}
+
+	values := map[string]float64{}
+	for _, c := range cols {
+		if !c.available(r) {
+			continue
+		}
+		if val, err := c.Eval(p); err == nil {
+			values[c.Name] = val
+		}
+	}
+	return values
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadConfigFile decodes a YAML or JSON file into v. JSON is valid YAML so a
+// single decoder handles both.
+func ReadConfigFile(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
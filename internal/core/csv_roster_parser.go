@@ -2,13 +2,33 @@ package core
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"strings"
 )
 
-type TextRosterParser struct{}
+// RowError is a row of a roster or INFO file that could not be read.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type TextRosterParser struct {
+	// RowErrors are the rows Parse skipped because they could not be read
+	RowErrors []error
+}
 
-func (p *TextRosterParser) Parse(data io.Reader) (*[][]string, error) {
+// ParseRows splits a roster style text file into whitespace separated rows,
+// skipping separator and blank lines.
+func (p *TextRosterParser) ParseRows(data io.Reader) (*[][]string, error) {
 	scanner := bufio.NewScanner(data)
 	rows := [][]string{}
 	for scanner.Scan() {
@@ -26,3 +46,54 @@ func (p *TextRosterParser) Parse(data io.Reader) (*[][]string, error) {
 
 	return &rows, nil
 }
+
+// Parse reads a roster file and maps each row onto a Player using the
+// column names from the roster's own header line. A row with the wrong
+// number of columns or an invalid value is skipped and added to RowErrors,
+// the file only fails when none of its rows can be read.
+func (p *TextRosterParser) Parse(data io.Reader) ([]string, []*Player, error) {
+	rows, err := p.ParseRows(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(*rows) == 0 {
+		return []string{}, []*Player{}, nil
+	}
+
+	header := make([]string, len((*rows)[0]))
+	for i, col := range (*rows)[0] {
+		header[i] = normalizeColumnName(col)
+	}
+
+	p.RowErrors = nil
+	players := make([]*Player, 0, len(*rows)-1)
+	for i, row := range (*rows)[1:] {
+		player, err := parsePlayerRow(header, row)
+		if err != nil {
+			p.RowErrors = append(p.RowErrors, &RowError{Row: i + 1, Err: err})
+			continue
+		}
+		players = append(players, player)
+	}
+
+	if len(players) == 0 && len(p.RowErrors) > 0 {
+		return nil, nil, fmt.Errorf("no readable rows, %s", p.RowErrors[0])
+	}
+
+	return header, players, nil
+}
+
+func parsePlayerRow(header []string, row []string) (*Player, error) {
+	if len(row) != len(header) {
+		return nil, fmt.Errorf("%d columns, expected %d", len(row), len(header))
+	}
+
+	player := &Player{}
+	for j, col := range header {
+		if err := player.Set(col, row[j]); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", col, row[j], err)
+		}
+	}
+	return player, nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTextRosterParser(t *testing.T) {
+	const header = "Name Age Nat Prs St\n---------------------\n"
+
+	tests := []struct {
+		name      string
+		content   string
+		players   []string
+		rowErrors []int
+		wantErr   bool
+	}{
+		{
+			name:    "valid rows",
+			content: header + "A_One 20 eng GK 15\nB_Two 21 sco DF 4\n",
+			players: []string{"A_One", "B_Two"},
+		},
+		{
+			name:      "missing column",
+			content:   header + "A_One 20 eng GK 15\nB_Two 21 sco DF\nC_Three 22 wal MF 3\n",
+			players:   []string{"A_One", "C_Three"},
+			rowErrors: []int{2},
+		},
+		{
+			name:      "extra column",
+			content:   header + "A_One 20 eng GK 15 9\nB_Two 21 sco DF 4\n",
+			players:   []string{"B_Two"},
+			rowErrors: []int{1},
+		},
+		{
+			name:      "invalid stat",
+			content:   header + "A_One 20 eng GK 15\nB_Two x sco DF 4\n",
+			players:   []string{"A_One"},
+			rowErrors: []int{2},
+		},
+		{
+			name:    "no readable rows",
+			content: header + "A_One 20 eng GK\n",
+			wantErr: true,
+		},
+		{
+			name:    "header only",
+			content: header,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &TextRosterParser{}
+			_, players, err := parser.Parse(strings.NewReader(tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, p := range players {
+				names = append(names, p.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.players, ",") {
+				t.Errorf("got players %v, want %v", names, tt.players)
+			}
+
+			rows := []int{}
+			for _, e := range parser.RowErrors {
+				var rowErr *RowError
+				if !errors.As(e, &rowErr) {
+					t.Fatalf("got %T, want a RowError", e)
+				}
+				rows = append(rows, rowErr.Row)
+			}
+			if len(rows) != len(tt.rowErrors) || (len(rows) > 0 && rows[0] != tt.rowErrors[0]) {
+				t.Errorf("got row errors %v, want %v", rows, tt.rowErrors)
+			}
+		})
+	}
+}
+
+func TestTextRosterParserWrapsValueErrors(t *testing.T) {
+	parser := &TextRosterParser{}
+	_, _, err := parser.Parse(strings.NewReader("Name Age\nA_One 20\nB_Two x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var numErr *strconv.NumError
+	if len(parser.RowErrors) != 1 || !errors.As(parser.RowErrors[0], &numErr) {
+		t.Errorf("got %v, want the strconv error wrapped", parser.RowErrors)
+	}
+}
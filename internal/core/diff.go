@@ -0,0 +1,207 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type ChangeKind string
+
+const (
+	ChangeNew        ChangeKind = "new"
+	ChangeDeparted   ChangeKind = "departed"
+	ChangeTransfer   ChangeKind = "transfer"
+	ChangeSkill      ChangeKind = "skill"
+	ChangeAbility    ChangeKind = "ability"
+	ChangeInjury     ChangeKind = "injury"
+	ChangeSuspension ChangeKind = "suspension"
+	ChangeWage       ChangeKind = "wage"
+	ChangeValue      ChangeKind = "value"
+)
+
+var changeOrder = []ChangeKind{
+	ChangeTransfer, ChangeNew, ChangeDeparted, ChangeSkill, ChangeAbility,
+	ChangeInjury, ChangeSuspension, ChangeWage, ChangeValue,
+}
+
+var (
+	diffSkillCols   = []string{"St", "Tk", "Ps", "Sh"}
+	diffAbilityCols = []string{"KAb", "TAb", "PAb", "SAb"}
+)
+
+// PlayerChange is a single difference for a player between two scrapes.
+type PlayerChange struct {
+	Player string     `json:"player"`
+	Club   string     `json:"club"`
+	Change ChangeKind `json:"change"`
+	Field  string     `json:"field,omitempty"`
+	From   string     `json:"from,omitempty"`
+	To     string     `json:"to,omitempty"`
+}
+
+type snapshotPlayer struct {
+	roster *RosterFile
+	player *Player
+}
+
+// indexPlayers keys each player by name and club, numbering players that
+// share a name within a club in roster order. The keys are built the same
+// way for every scrape, so a player keeps its key as long as it stays at its
+// club. The keys are returned in roster order.
+func indexPlayers(rosters []*RosterFile) ([]string, map[string]snapshotPlayer) {
+	keys := []string{}
+	index := map[string]snapshotPlayer{}
+	for _, r := range rosters {
+		counts := map[string]int{}
+		for _, p := range r.Players {
+			counts[p.Name]++
+			key := fmt.Sprintf("%s@%s#%d", p.Name, r.Code, counts[p.Name])
+			keys = append(keys, key)
+			index[key] = snapshotPlayer{roster: r, player: p}
+		}
+	}
+	return keys, index
+}
+
+// DiffRosters compares two scrapes and returns every transfer, new and
+// departed player, skill and ability change, new injury or suspension and
+// wage or value change.
+func DiffRosters(before []*RosterFile, after []*RosterFile) []PlayerChange {
+	oldKeys, oldPlayers := indexPlayers(before)
+	newKeys, newPlayers := indexPlayers(after)
+	changes := []PlayerChange{}
+
+	// players still at their club are matched by key, the ones left over are
+	// matched by name so a move shows as a transfer
+	pairs := [][2]snapshotPlayer{}
+	left := map[string][]snapshotPlayer{}
+	for _, key := range oldKeys {
+		o := oldPlayers[key]
+		if n, ok := newPlayers[key]; ok {
+			pairs = append(pairs, [2]snapshotPlayer{o, n})
+		} else {
+			left[o.player.Name] = append(left[o.player.Name], o)
+		}
+	}
+	for _, key := range newKeys {
+		n := newPlayers[key]
+		if _, ok := oldPlayers[key]; ok {
+			continue
+		}
+		if candidates := left[n.player.Name]; len(candidates) > 0 {
+			pairs = append(pairs, [2]snapshotPlayer{candidates[0], n})
+			left[n.player.Name] = candidates[1:]
+			continue
+		}
+		changes = append(changes, PlayerChange{Player: n.player.Name, Club: n.roster.Code, Change: ChangeNew})
+	}
+	for _, departed := range left {
+		for _, o := range departed {
+			changes = append(changes, PlayerChange{Player: o.player.Name, Club: o.roster.Code, Change: ChangeDeparted})
+		}
+	}
+
+	for _, pair := range pairs {
+		o, n := pair[0], pair[1]
+		change := func(kind ChangeKind, field string, from string, to string) {
+			changes = append(changes, PlayerChange{Player: n.player.Name, Club: n.roster.Code, Change: kind, Field: field, From: from, To: to})
+		}
+
+		if o.roster.Code != n.roster.Code {
+			change(ChangeTransfer, "Club", o.roster.Code, n.roster.Code)
+		}
+
+		for _, col := range diffSkillCols {
+			if o.player.Int(col) != n.player.Int(col) {
+				change(ChangeSkill, col, strconv.Itoa(o.player.Int(col)), strconv.Itoa(n.player.Int(col)))
+			}
+		}
+		for _, col := range diffAbilityCols {
+			if o.player.Int(col) != n.player.Int(col) {
+				change(ChangeAbility, col, strconv.Itoa(o.player.Int(col)), strconv.Itoa(n.player.Int(col)))
+			}
+		}
+
+		if n.player.Inj > 0 && o.player.Inj == 0 {
+			change(ChangeInjury, "Inj", strconv.Itoa(o.player.Inj), strconv.Itoa(n.player.Inj))
+		}
+		if n.player.Sus > 0 && o.player.Sus == 0 {
+			change(ChangeSuspension, "Sus", strconv.Itoa(o.player.Sus), strconv.Itoa(n.player.Sus))
+		}
+
+		if o.player.hasInfo() && n.player.hasInfo() {
+			if o.player.Wage != n.player.Wage {
+				change(ChangeWage, "Wage", formatMoney(o.player.Wage), formatMoney(n.player.Wage))
+			}
+			if o.player.Value != n.player.Value {
+				change(ChangeValue, "Value", formatMoney(o.player.Value), formatMoney(n.player.Value))
+			}
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b PlayerChange) int {
+		if c := slices.Index(changeOrder, a.Change) - slices.Index(changeOrder, b.Change); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Club, b.Club); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Player, b.Player); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return changes
+}
+
+var diffHeaders = []string{"Player", "Club", "Change", "Field", "From", "To"}
+
+func (c PlayerChange) values() []string {
+	return []string{c.Player, c.Club, string(c.Change), c.Field, c.From, c.To}
+}
+
+// WriteDiff writes the changes as csv, json or a terminal table.
+func WriteDiff(w io.Writer, changes []PlayerChange, format string) error {
+	switch strings.ToLower(format) {
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write(diffHeaders)
+		for _, c := range changes {
+			writer.Write(c.values())
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	case "table":
+		t := table.NewWriter()
+		t.SetOutputMirror(w)
+		header := table.Row{}
+		for _, h := range diffHeaders {
+			header = append(header, h)
+		}
+		t.AppendHeader(header)
+		for _, c := range changes {
+			row := table.Row{}
+			for _, v := range c.values() {
+				row = append(row, v)
+			}
+			t.AppendRow(row)
+		}
+		t.SetStyle(table.StyleLight)
+		t.Render()
+		return nil
+	default:
+		return fmt.Errorf("unsupported diff format: %s", format)
+	}
+}
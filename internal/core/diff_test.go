@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func diffRoster(code string, players ...*Player) *RosterFile {
+	return &RosterFile{Code: code, Players: players}
+}
+
+func TestDiffRosters(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []*RosterFile
+		after  []*RosterFile
+		want   []string
+	}{
+		{
+			name:   "transfer",
+			before: []*RosterFile{diffRoster("ARS", &Player{Name: "A_One"}), diffRoster("CHE")},
+			after:  []*RosterFile{diffRoster("ARS"), diffRoster("CHE", &Player{Name: "A_One", St: 12})},
+			want:   []string{"transfer A_One CHE Club ARS>CHE", "skill A_One CHE St 0>12"},
+		},
+		{
+			name:   "new and departed",
+			before: []*RosterFile{diffRoster("ARS", &Player{Name: "A_One"})},
+			after:  []*RosterFile{diffRoster("ARS", &Player{Name: "B_Two"})},
+			want:   []string{"new B_Two ARS", "departed A_One ARS"},
+		},
+		{
+			name:   "name shared in one scrape only",
+			before: []*RosterFile{diffRoster("ARS", &Player{Name: "A_One"}), diffRoster("CHE")},
+			after:  []*RosterFile{diffRoster("ARS", &Player{Name: "A_One"}), diffRoster("CHE", &Player{Name: "A_One"})},
+			want:   []string{"new A_One CHE"},
+		},
+		{
+			name: "transfer of a shared name",
+			before: []*RosterFile{
+				diffRoster("ARS", &Player{Name: "A_One", Age: 20}),
+				diffRoster("CHE", &Player{Name: "A_One", Age: 30}),
+				diffRoster("LEE"),
+			},
+			after: []*RosterFile{
+				diffRoster("ARS", &Player{Name: "A_One", Age: 20}),
+				diffRoster("CHE"),
+				diffRoster("LEE", &Player{Name: "A_One", Age: 30}),
+			},
+			want: []string{"transfer A_One LEE Club CHE>LEE"},
+		},
+		{
+			name: "shared name at one club",
+			before: []*RosterFile{diffRoster("ARS",
+				&Player{Name: "A_One", St: 10},
+				&Player{Name: "A_One", St: 5, Squad: SquadAcademy},
+			)},
+			after: []*RosterFile{diffRoster("ARS",
+				&Player{Name: "A_One", St: 10},
+				&Player{Name: "A_One", St: 6, Squad: SquadAcademy},
+			)},
+			want: []string{"skill A_One ARS St 5>6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, c := range DiffRosters(tt.before, tt.after) {
+				s := fmt.Sprintf("%s %s %s", c.Change, c.Player, c.Club)
+				if c.Field != "" {
+					s += fmt.Sprintf(" %s %s>%s", c.Field, c.From, c.To)
+				}
+				got = append(got, s)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got changes\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
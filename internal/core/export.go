@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type ExportFormat string
+
+const (
+	FormatCsv    ExportFormat = "csv"
+	FormatJson   ExportFormat = "json"
+	FormatNdjson ExportFormat = "ndjson"
+	FormatXlsx   ExportFormat = "xlsx"
+)
+
+var ExportFormats = []ExportFormat{FormatCsv, FormatJson, FormatNdjson, FormatXlsx}
+
+func ParseExportFormat(value string) (ExportFormat, error) {
+	for _, f := range ExportFormats {
+		if strings.EqualFold(value, string(f)) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported export format: %s", value)
+}
+
+// SelectsColumns reports whether the format can be limited to some of the
+// columns. JSON exports always hold the whole player record.
+func (f ExportFormat) SelectsColumns() bool {
+	return f == FormatCsv || f == FormatXlsx
+}
+
+// ScrapeMeta describes the scrape that produced an export.
+type ScrapeMeta struct {
+	Game      string    `json:"game"`
+	SourceUrl string    `json:"sourceUrl"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   string    `json:"version"`
+	Errors    []string  `json:"errors"`
+}
+
+type ExportOptions struct {
+	OutputDir        string
+	FileNamePrefix   string
+	Title            string
+	UseExcelFormulas bool
+	ComputedColumns  []*ComputedColumn
+	// Columns selects the exported columns and their order, all by default
+	Columns []string
+	Sort    []SortKey
+	// ExcludeAcademy leaves out the academy and youth players
+	ExcludeAcademy bool
+	// Leagues limits the export to the clubs of these leagues
+	Leagues []string
+	// Squads limits the export to the players of these squads
+	Squads []Squad
+	Meta   ScrapeMeta
+}
+
+// filterRosters returns copies of the rosters holding only the clubs and
+// players the options export.
+func filterRosters(rosters []*RosterFile, opts ExportOptions) []*RosterFile {
+	if !opts.ExcludeAcademy && len(opts.Leagues) == 0 && len(opts.Squads) == 0 {
+		return rosters
+	}
+
+	filtered := []*RosterFile{}
+	for _, r := range rosters {
+		if len(opts.Leagues) > 0 && !slices.ContainsFunc(opts.Leagues, func(l string) bool { return strings.EqualFold(l, r.League) }) {
+			continue
+		}
+
+		copied := *r
+		if r.Players != nil {
+			copied.Players = slices.DeleteFunc(slices.Clone(r.Players), func(p *Player) bool {
+				if opts.ExcludeAcademy && p.Squad != SquadSenior {
+					return true
+				}
+				return len(opts.Squads) > 0 && !slices.Contains(opts.Squads, p.Squad)
+			})
+		}
+		filtered = append(filtered, &copied)
+	}
+	return filtered
+}
+
+// Export writes the rosters to a new file in the given format and returns
+// the absolute path of the file.
+func Export(rosters []*RosterFile, format ExportFormat, opts ExportOptions) (string, error) {
+	if opts.Meta.Timestamp.IsZero() {
+		opts.Meta.Timestamp = time.Now()
+	}
+	if len(opts.Columns) > 0 && !format.SelectsColumns() {
+		return "", fmt.Errorf("%s exports can't select columns", format)
+	}
+	rosters = filterRosters(rosters, opts)
+
+	switch format {
+	case FormatCsv:
+		return ExportToCsv(rosters, opts)
+	case FormatJson:
+		return ExportToJson(rosters, opts)
+	case FormatNdjson:
+		return ExportToNdjson(rosters, opts)
+	case FormatXlsx:
+		return ExportToXlsx(rosters, opts)
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func createExportFile(outputDir string, fileNamePrefix string, timestamp time.Time, ext string) (*os.File, error) {
+	if _, err := os.Stat(outputDir); err != nil {
+		if os.IsNotExist(err) {
+			color.New(color.FgBlue).Println("Output directory does not exist, it will be created.")
+			os.Mkdir(outputDir, 0755)
+		}
+	}
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	outputFile := path.Join(outputDir, fmt.Sprintf("%s%d.%s", fileNamePrefix, timestamp.Unix(), ext))
+	return os.Create(outputFile)
+}
+
+func exportPath(file *os.File) string {
+	absPath, err := filepath.Abs(file.Name())
+	if err != nil {
+		color.Yellow("Failed to get absolute path: %v", err)
+		absPath = file.Name()
+	}
+
+	color.Green("Export file\t\t ... %s.", absPath)
+	return absPath
+}
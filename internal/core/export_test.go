@@ -0,0 +1,26 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExportColumnsOnlyForTables(t *testing.T) {
+	rosters := []*RosterFile{{Code: "ARS", Columns: []string{"Name", "Age"}, Players: []*Player{{Name: "A_One", Age: 20}}}}
+
+	for _, format := range ExportFormats {
+		dir := t.TempDir()
+		_, err := Export(rosters, format, ExportOptions{OutputDir: dir, Columns: []string{"Name"}})
+		if format.SelectsColumns() && err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+		if !format.SelectsColumns() {
+			if err == nil {
+				t.Errorf("%s: exported with selected columns, want an error", format)
+			}
+			if files, _ := os.ReadDir(dir); len(files) != 0 {
+				t.Errorf("%s: got %d files, want none", format, len(files))
+			}
+		}
+	}
+}
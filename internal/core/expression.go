@@ -0,0 +1,295 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A small arithmetic expression language for computed columns. It supports
+// numbers, column names, + - * /, unary minus and parentheses. Column names
+// containing spaces are written in brackets, e.g. [Mkt Value].
+
+var errDivideByZero = errors.New("division by zero")
+
+type exprNode interface {
+	eval(lookup func(col string) (float64, error)) (float64, error)
+	formula(ref func(col string) string) string
+	columns(cols []string) []string
+}
+
+type numberNode float64
+
+func (n numberNode) eval(func(string) (float64, error)) (float64, error) {
+	return float64(n), nil
+}
+
+func (n numberNode) formula(func(string) string) string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+func (n numberNode) columns(cols []string) []string {
+	return cols
+}
+
+type columnNode string
+
+func (n columnNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	return lookup(string(n))
+}
+
+func (n columnNode) formula(ref func(string) string) string {
+	return ref(string(n))
+}
+
+func (n columnNode) columns(cols []string) []string {
+	return append(cols, string(n))
+}
+
+type negateNode struct {
+	operand exprNode
+}
+
+func (n negateNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	val, err := n.operand.eval(lookup)
+	return -val, err
+}
+
+func (n negateNode) formula(ref func(string) string) string {
+	return "-" + n.operand.formula(ref)
+}
+
+func (n negateNode) columns(cols []string) []string {
+	return n.operand.columns(cols)
+}
+
+type groupNode struct {
+	inner exprNode
+}
+
+func (n groupNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	return n.inner.eval(lookup)
+}
+
+func (n groupNode) formula(ref func(string) string) string {
+	return "(" + n.inner.formula(ref) + ")"
+}
+
+func (n groupNode) columns(cols []string) []string {
+	return n.inner.columns(cols)
+}
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n binaryNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	left, err := n.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, errDivideByZero
+		}
+		return left / right, nil
+	}
+}
+
+func (n binaryNode) formula(ref func(string) string) string {
+	return fmt.Sprintf("%s %c %s", n.left.formula(ref), n.op, n.right.formula(ref))
+}
+
+func (n binaryNode) columns(cols []string) []string {
+	return n.right.columns(n.left.columns(cols))
+}
+
+// Expression is a parsed computed column expression.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+func ParseExpression(source string) (*Expression, error) {
+	p := &exprParser{input: source}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Columns returns the columns the expression refers to.
+func (e *Expression) Columns() []string {
+	return e.root.columns([]string{})
+}
+
+// Eval calculates the expression, looking up each column with the given
+// function.
+func (e *Expression) Eval(lookup func(col string) (float64, error)) (float64, error) {
+	return e.root.eval(lookup)
+}
+
+// Formula returns the expression as a spreadsheet formula, without the
+// leading =, with each column replaced by the given cell reference.
+func (e *Expression) Formula(ref func(col string) string) string {
+	return e.root.formula(ref)
+}
+
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expression %q at %d: %s", p.input, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	c := p.peek()
+	start := p.pos
+	switch {
+	case c == 0:
+		return nil, p.errorf("unexpected end")
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return groupNode{inner: inner}, nil
+	case c == '[':
+		end := strings.IndexByte(p.input[start:], ']')
+		if end < 0 {
+			return nil, p.errorf("missing ]")
+		}
+		p.pos = start + end + 1
+		return columnNode(strings.TrimSpace(p.input[start+1 : start+end])), nil
+	case c == '.' || isDigit(c):
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || isDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		text := p.input[start:p.pos]
+		val, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number %q", text)
+		}
+		return numberNode(val), nil
+	case isNameRune(p.runeAt(start), true):
+		for p.pos < len(p.input) && isNameRune(p.runeAt(p.pos), false) {
+			_, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			p.pos += size
+		}
+		return columnNode(p.input[start:p.pos]), nil
+	default:
+		return nil, p.errorf("unexpected %q", p.runeAt(start))
+	}
+}
+
+// runeAt decodes the rune at the byte offset, column names aren't limited
+// to ASCII.
+func (p *exprParser) runeAt(pos int) rune {
+	r, _ := utf8.DecodeRuneInString(p.input[pos:])
+	return r
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isNameRune reports whether the rune can be part of a column name, a name
+// can't start with a digit.
+func isNameRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExpressionEval(t *testing.T) {
+	columns := map[string]float64{"Gls": 6, "Min": 540, "Größe": 180, "Mkt Value": 12.5, "Zero": 0}
+	lookup := func(col string) (float64, error) {
+		val, ok := columns[col]
+		if !ok {
+			return 0, errors.New("unknown column " + col)
+		}
+		return val, nil
+	}
+
+	tests := []struct {
+		expr    string
+		want    float64
+		columns []string
+		err     error
+	}{
+		{expr: "1 + 2 * 3", want: 7},
+		{expr: "(1 + 2) * 3", want: 9},
+		{expr: "10 - 4 - 3", want: 3},
+		{expr: "8 / 4 / 2", want: 1},
+		{expr: "-2 * 3 + 1", want: -5},
+		{expr: "2 - -1", want: 3},
+		{expr: ".5 * 4", want: 2},
+		{expr: "Gls / Min * 90", want: 1, columns: []string{"Gls", "Min"}},
+		{expr: "Größe / 2", want: 90, columns: []string{"Größe"}},
+		{expr: "[Mkt Value] * 2", want: 25, columns: []string{"Mkt Value"}},
+		{expr: "Gls / Zero", columns: []string{"Gls", "Zero"}, err: errDivideByZero},
+		{expr: "Gls / (Min - 540)", columns: []string{"Gls", "Min"}, err: errDivideByZero},
+		{expr: "Ass * 2", columns: []string{"Ass"}, err: errors.New("unknown column Ass")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.columns != nil && strings.Join(expr.Columns(), ",") != strings.Join(tt.columns, ",") {
+				t.Errorf("got columns %v, want %v", expr.Columns(), tt.columns)
+			}
+
+			got, err := expr.Eval(lookup)
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("got error %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1 + 2", "[Mkt Value", "1.2.3", "Gls $ 2", "Gls Min", "2Gls"} {
+		if _, err := ParseExpression(expr); err == nil {
+			t.Errorf("%q: got no error", expr)
+		}
+	}
+}
+
+func TestComputedColumnEval(t *testing.T) {
+	p := &Player{Gls: 3, Min: 0, Extra: map[string]string{"Größe": "180", "Pos": "GK"}}
+
+	tests := []struct {
+		def     string
+		want    float64
+		wantErr bool
+	}{
+		{def: "GlsPerMin = Gls / Min", want: 0},
+		{def: "Half = Größe / 2", want: 90},
+		{def: "Double = gls * 2", want: 6},
+		{def: "Unknown = Foo * 2", wantErr: true},
+		{def: "Text = Pos * 2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			c, err := ParseComputedColumn(tt.def)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.Eval(p)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckComputedColumns(t *testing.T) {
+	tests := []struct {
+		defs    []string
+		wantErr bool
+	}{
+		{defs: []string{"GlsPer90 = Gls / Min * 90", "Rating = (Sh*2 + Ps + Ag) / 4"}},
+		{defs: []string{"Rating = Sh * 2", "Double = Rating * 2"}, wantErr: true},
+		{defs: []string{"Double = rating * 2", "Rating = Sh * 2"}, wantErr: true},
+		{defs: []string{"GlsPer90 = [Gls/min] * 90"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.defs, "; "), func(t *testing.T) {
+			cols := []*ComputedColumn{}
+			for _, def := range tt.defs {
+				c, err := ParseComputedColumn(def)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cols = append(cols, c)
+			}
+			if err := CheckComputedColumns(cols); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,223 +4,244 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
-	"net/url"
 	"os"
-	"path/filepath"
-	"strings"
 	"sync"
 	"time"
-
-	"github.com/corpix/uarand"
 )
 
+// FileRosterLoader loads rosters with a pool of MaxConcurrent workers. The
+// OnLoaded and OnError callbacks run one at a time on the goroutine calling
+// Load, which returns once all of them have been delivered.
 type FileRosterLoader struct {
 	RemoteUrl     string
 	DownloadFiles bool
 	Dir           string
 	MaxConcurrent int
-	OnLoaded      func(*RosterFile)
-	OnError       func(error)
+	Transport     http.RoundTripper
+	// RequestTimeout limits each download, no limit when zero
+	RequestTimeout time.Duration
+	RetryPolicy    *RetryPolicy
+	OnLoaded       func(*RosterFile)
+	OnError        func(error)
 }
 
-func (l *FileRosterLoader) Load(rosters []*RosterFile, ctx context.Context) {
-	if l.DownloadFiles {
-		if _, err := os.Stat(l.Dir); err != nil {
-			if os.IsNotExist(err) {
-				if l.OnError != nil {
-					l.OnError(fmt.Errorf("directory does not exist: %s", l.Dir))
-				}
-			}
-		}
+func (l *FileRosterLoader) retryPolicy() RetryPolicy {
+	if l.RetryPolicy != nil {
+		return *l.RetryPolicy
 	}
+	return DefaultRetryPolicy
+}
 
-	// Create a semaphore with a buffer size of maxConcurrent
-	sem := make(chan struct{}, int(math.Max(1, float64(l.MaxConcurrent))))
-	defer close(sem)
+// sleep waits for the duration unless the context is cancelled first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	errCh := make(chan error)
-	defer close(errCh)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
 
-	resultCh := make(chan *RosterFile)
-	defer close(resultCh)
+func (l *FileRosterLoader) downloadFile(ctx context.Context, client *http.Client, filePath string) ([]byte, error) {
+	fileUrl, err := resolveFileUrl(l.RemoteUrl, filePath)
+	if err != nil {
+		return nil, err
+	}
 
-	var wg sync.WaitGroup
+	if l.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.RequestTimeout)
+		defer cancel()
+	}
 
-	parser := &TextRosterParser{}
-	// Create a new HTTP client
-	client := &http.Client{}
-	downloadFile := func(filePath string) ([]byte, error) {
-		fileUrl, err := url.JoinPath(l.RemoteUrl, filePath)
-		if err != nil {
-			return nil, err
-		}
+	req, err := http.NewRequestWithContext(ctx, "GET", fileUrl, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		// Create a new request with the URL you want to access
-		req, err := http.NewRequest("GET", fileUrl, nil)
-		if err != nil {
-			return nil, err
-		}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-		req.Header.Set("User-Agent", uarand.GetRandom())
+	if res.StatusCode != http.StatusOK {
+		return nil, newHttpStatusError(fileUrl, res.StatusCode, res.Header)
+	}
 
-		// Send the request
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
 
-		if res.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("file download failed: %s - %s", fileUrl, res.Status)
-		}
+// location returns the URL or local path a file of the roster is read from.
+func (l *FileRosterLoader) location(roster *RosterFile, filePath string) string {
+	if l.RemoteUrl == "" {
+		return localRosterPath(l.Dir, roster)
+	}
+	if fileUrl, err := resolveFileUrl(l.RemoteUrl, filePath); err == nil {
+		return fileUrl
+	}
+	return filePath
+}
 
-		return io.ReadAll(res.Body)
+// loadOptional downloads and parses an academy or INFO file, a failure is
+// recorded on the roster without failing it.
+func (l *FileRosterLoader) loadOptional(ctx context.Context, client *http.Client, roster *RosterFile, kind FileKind, filePath string, parse func(*RosterFile, []byte) error) error {
+	if filePath == "" {
+		return nil
 	}
-	loadCsvFile := func(filePath string) (*[][]string, error) {
-		file, err := os.Open(filePath)
-		if err == nil {
-			defer file.Close()
-			return parser.Parse(file)
-		} else {
-			return nil, err
-		}
+
+	content, err := l.downloadFile(ctx, client, filePath)
+	if err == nil {
+		err = parse(roster, content)
 	}
-	loadAcademy := func(roster *RosterFile) error {
-		time.Sleep(50 * time.Millisecond)
-		content, err := downloadFile(roster.AcademyFileLocation)
-		if err != nil {
-			return err
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		roster.Errors = append(roster.Errors, newScrapeError(roster, kind, l.location(roster, filePath), err))
+	}
+	return nil
+}
 
-		academyRows, err := parser.Parse(strings.NewReader(string(content)))
-		if err != nil {
+func (l *FileRosterLoader) loadAndParse(ctx context.Context, client *http.Client, roster *RosterFile) error {
+	roster.Errors = nil
+	if l.RemoteUrl == "" {
+		if err := loadLocalRoster(l.Dir, roster); err != nil {
 			return err
 		}
+		loadLocalInfo(l.Dir, roster)
+		return nil
+	}
 
-		if len(*academyRows) > 0 {
-			*roster.Rows = append(*roster.Rows, (*academyRows)[1:]...)
-		}
+	contents, err := l.downloadFile(ctx, client, roster.FileLocation)
+	if err != nil {
+		return err
+	}
+	if err := parseRosterContent(roster, contents); err != nil {
+		return err
+	}
 
-		return nil
+	if err := l.loadOptional(ctx, client, roster, FileAcademy, roster.AcademyFileLocation, parseAcademyContent); err != nil {
+		return err
+	}
+	if err := l.loadOptional(ctx, client, roster, FileInfo, roster.InfoFileLocation, parseInfoContent); err != nil {
+		return err
 	}
-	loadInfo := func(roster *RosterFile) error {
-		time.Sleep(50 * time.Millisecond)
-		infoContent, err := downloadFile(roster.InfoFileLocation)
-		if err != nil {
-			return err
-		}
 
-		infoRows, err := parser.Parse(strings.NewReader(string(infoContent)))
-		if err != nil {
+	if l.DownloadFiles {
+		if err := saveRosterFile(l.Dir, roster, contents); err != nil {
 			return err
 		}
-
-		roster.InfoRows = infoRows
-		return nil
 	}
-	loadAndParse := func(roster *RosterFile) error {
-		localPath := filepath.Join(l.Dir, roster.Code+".txt")
-		if l.RemoteUrl != "" {
-			// check remote
-			contents, err := downloadFile(roster.FileLocation)
-			if err != nil {
-				return err
-			}
 
-			parsed, err := parser.Parse(strings.NewReader(string(contents)))
-			if err != nil {
-				return err
-			}
-			roster.Rows = parsed
+	// a local INFO file takes precedence over the downloaded one
+	loadLocalInfo(l.Dir, roster)
+	return nil
+}
 
-			// scrape academy
-			if roster.AcademyFileLocation != "" {
-				loadAcademy(roster)
-			}
+// load loads a roster, retrying as the policy allows.
+func (l *FileRosterLoader) load(ctx context.Context, client *http.Client, roster *RosterFile) rosterOutcome {
+	start := time.Now()
+	policy := l.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			break
+		}
 
-			// scrape info remotely
-			if roster.InfoFileLocation != "" {
-				loadInfo(roster)
-			}
+		roster.Attempts = attempt
+		err := l.loadAndParse(ctx, client, roster)
+		roster.Duration = time.Since(start)
+		if err == nil {
+			return rosterOutcome{roster: roster}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if !policy.ShouldRetry(err, attempt) {
+			return rosterOutcome{roster: roster, err: newScrapeError(roster, FileRoster, l.location(roster, roster.FileLocation), err)}
+		}
+		if sleep(ctx, policy.Delay(err, attempt)) != nil {
+			break
+		}
+	}
 
-			if l.DownloadFiles {
-				// save roster locally
-				err = os.WriteFile(localPath, contents, 0644)
-				if err != nil {
-					return err
-				}
-			}
-		} else {
-			rows, err := loadCsvFile(localPath)
-			if err != nil && !os.IsNotExist(err) {
-				return err
-			}
+	// a roster interrupted by the cancellation counts as not attempted
+	roster.Columns, roster.Players, roster.Info = nil, nil, nil
+	return rosterOutcome{roster: roster, skipped: true}
+}
 
-			roster.Rows = rows
-		}
+func (l *FileRosterLoader) Load(rosters []*RosterFile, ctx context.Context) *LoadResult {
+	result := newLoadResult()
+	start := time.Now()
 
-		// load info locally if exists
-		infLocalPath := filepath.Join(l.Dir, fmt.Sprintf("INFO_%s.txt", roster.Code))
-		if infRows, err := loadCsvFile(infLocalPath); err == nil {
-			roster.InfoRows = infRows
+	if l.DownloadFiles {
+		if _, err := os.Stat(l.Dir); os.IsNotExist(err) {
+			err := fmt.Errorf("directory does not exist: %s", l.Dir)
+			result.Errors = append(result.Errors, err)
+			if l.OnError != nil {
+				l.OnError(err)
+			}
 		}
-
-		return nil
 	}
 
-	for _, r := range rosters {
+	client := &http.Client{Transport: l.Transport}
+	jobs := make(chan *RosterFile)
+	outcomes := make(chan rosterOutcome)
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(1, min(l.MaxConcurrent, len(rosters))); i++ {
 		wg.Add(1)
-		go func(roster *RosterFile) {
+		go func() {
 			defer wg.Done()
-
-			// Acquire a semaphore
-			sem <- struct{}{}
-
-			var done bool = false
-			for roster.Failures < 3 && !done {
-
-				time.Sleep(50 * time.Millisecond)
-
-				select {
-				case <-ctx.Done():
-					// If the context is cancelled, do nothing
-				default:
-					if err := loadAndParse(roster); err != nil {
-						if roster.Failures < 3 {
-							// allow retry
-							roster.Failures++
-						} else {
-							done = true
-							errCh <- err
-						}
-					} else {
-						done = true
-						resultCh <- roster
-					}
-				}
+			for roster := range jobs {
+				outcomes <- l.load(ctx, client, roster)
 			}
-
-			// Release the semaphore
-			<-sem
-		}(r)
+		}()
 	}
 
+	// hand out the rosters until all are taken or the load is cancelled, the
+	// ones not handed out by then are skipped
+	wg.Add(1)
 	go func() {
-		for {
+		defer wg.Done()
+		defer close(jobs)
+		for i, r := range rosters {
 			select {
-			case ros, ok := <-resultCh:
-				if ok && l.OnLoaded != nil {
-					l.OnLoaded(ros)
-				}
-			case err, ok := <-errCh:
-				if ok && l.OnError != nil {
-					l.OnError(err)
+			case jobs <- r:
+			case <-ctx.Done():
+				for _, rest := range rosters[i:] {
+					outcomes <- rosterOutcome{roster: rest, skipped: true}
 				}
+				return
 			}
 		}
 	}()
 
-	wg.Wait()
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	for o := range outcomes {
+		result.add(o)
+		switch {
+		case o.skipped:
+		case o.err != nil:
+			if l.OnError != nil {
+				l.OnError(o.err)
+			}
+		default:
+			if l.OnLoaded != nil {
+				l.OnLoaded(o.roster)
+			}
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.Cancelled = ctx.Err() != nil
+	return result
 }
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// HttpCache is an http.RoundTripper that keeps an on-disk copy of every
+// successful GET response that has an ETag or Last-Modified header. Later
+// requests for the same URL are sent as conditional requests and a 304
+// response is served from the cache.
+type HttpCache struct {
+	Dir       string
+	Transport http.RoundTripper
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry is the metadata of a cached response, its body is stored next
+// to it. The checksum ties the two files together, a body that doesn't match
+// it is not used.
+type cacheEntry struct {
+	Url          string      `json:"url"`
+	ETag         string      `json:"etag"`
+	LastModified string      `json:"lastModified"`
+	Header       http.Header `json:"header"`
+	Sha256       string      `json:"sha256"`
+}
+
+func NewHttpCache(dir string, transport http.RoundTripper) (*HttpCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &HttpCache{Dir: dir, Transport: transport}, nil
+}
+
+// Hits returns the number of responses served from the cache.
+func (c *HttpCache) Hits() int64 {
+	return c.hits.Load()
+}
+
+// Misses returns the number of responses downloaded in full.
+func (c *HttpCache) Misses() int64 {
+	return c.misses.Load()
+}
+
+func (c *HttpCache) transport() http.RoundTripper {
+	if c.Transport != nil {
+		return c.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (c *HttpCache) paths(url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, key+".json"), filepath.Join(c.Dir, key+".body")
+}
+
+func (c *HttpCache) load(url string) (*cacheEntry, []byte) {
+	metaPath, bodyPath := c.paths(url)
+	metaContent, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(metaContent, entry); err != nil || entry.Url != url {
+		return nil, nil
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != entry.Sha256 {
+		return nil, nil
+	}
+	return entry, body
+}
+
+func (c *HttpCache) store(url string, res *http.Response, body []byte) error {
+	sum := sha256.Sum256(body)
+	entry := cacheEntry{
+		Url:          url,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Header:       res.Header.Clone(),
+		Sha256:       hex.EncodeToString(sum[:]),
+	}
+	metaContent, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	metaPath, bodyPath := c.paths(url)
+	if err := c.writeFile(bodyPath, body); err != nil {
+		return err
+	}
+	return c.writeFile(metaPath, metaContent)
+}
+
+// writeFile writes the file through a temporary file in the cache directory
+// so a reader never sees it half written.
+func (c *HttpCache) writeFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(c.Dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *HttpCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return c.transport().RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	entry, cached := c.load(url)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	res, err := c.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		res.Body.Close()
+		c.hits.Add(1)
+
+		header := entry.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("X-Cache", "HIT")
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         res.Proto,
+			ProtoMajor:    res.ProtoMajor,
+			ProtoMinor:    res.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached)),
+			ContentLength: int64(len(cached)),
+			Request:       req,
+		}, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return res, nil
+	}
+
+	c.misses.Add(1)
+	if res.Header.Get("ETag") == "" && res.Header.Get("Last-Modified") == "" {
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed write only means the next request isn't conditional
+	c.store(url, res, body)
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	return res, nil
+}
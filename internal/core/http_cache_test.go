@@ -0,0 +1,108 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+// newEtagServer serves the body with an ETag and answers a request for the
+// current version with a 304. It counts the conditional requests.
+func newEtagServer(t *testing.T, body string, conditional *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func cacheGet(t *testing.T, cache *HttpCache, url string) (string, string) {
+	t.Helper()
+
+	res, err := (&http.Client{Transport: cache}).Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", res.StatusCode)
+	}
+	return string(body), res.Header.Get("X-Cache")
+}
+
+func TestHttpCacheRevalidation(t *testing.T) {
+	var conditional atomic.Int64
+	srv := newEtagServer(t, "Name Age\nA_One 20\n", &conditional)
+
+	cache, err := NewHttpCache(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, hit := cacheGet(t, cache, srv.URL+"/ARS.txt"); body != "Name Age\nA_One 20\n" || hit != "" {
+		t.Errorf("got %q from %q on the first request, want the download", body, hit)
+	}
+	if body, hit := cacheGet(t, cache, srv.URL+"/ARS.txt"); body != "Name Age\nA_One 20\n" || hit != "HIT" {
+		t.Errorf("got %q from %q after a 304, want the cached body", body, hit)
+	}
+	if files, _ := os.ReadDir(cache.Dir); len(files) != 2 {
+		t.Errorf("got %d files in the cache, want the body and its metadata", len(files))
+	}
+	if conditional.Load() != 1 || cache.Hits() != 1 || cache.Misses() != 1 {
+		t.Errorf("got %d conditional requests, %d hits and %d misses, want 1 of each", conditional.Load(), cache.Hits(), cache.Misses())
+	}
+}
+
+func TestHttpCacheBrokenEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(metaPath string, bodyPath string) error
+	}{
+		{name: "missing meta", damage: func(metaPath, _ string) error { return os.Remove(metaPath) }},
+		{name: "corrupt meta", damage: func(metaPath, _ string) error { return os.WriteFile(metaPath, []byte("{"), 0644) }},
+		{name: "missing body", damage: func(_, bodyPath string) error { return os.Remove(bodyPath) }},
+		{name: "body of another response", damage: func(_, bodyPath string) error { return os.WriteFile(bodyPath, []byte("other"), 0644) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conditional atomic.Int64
+			srv := newEtagServer(t, "Name Age\nA_One 20\n", &conditional)
+
+			cache, err := NewHttpCache(t.TempDir(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			url := srv.URL + "/ARS.txt"
+			cacheGet(t, cache, url)
+
+			if err := tt.damage(cache.paths(url)); err != nil {
+				t.Fatal(err)
+			}
+			if body, hit := cacheGet(t, cache, url); body != "Name Age\nA_One 20\n" || hit != "" {
+				t.Errorf("got %q from %q, want the download", body, hit)
+			}
+			if conditional.Load() != 0 || cache.Misses() != 2 {
+				t.Errorf("got %d conditional requests and %d misses, want a miss", conditional.Load(), cache.Misses())
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ContextTransport is an http.RoundTripper that binds every request to a
+// context and a timeout. It lets the colly collectors, which make their
+// requests without a context, stop when the scrape is cancelled.
+type ContextTransport struct {
+	// Context cancels the requests in flight when it is done
+	Context context.Context
+	// Timeout limits each request including reading the body, no limit when
+	// zero
+	Timeout   time.Duration
+	Transport http.RoundTripper
+}
+
+func (t *ContextTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (t *ContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancelRequest := context.WithCancel(req.Context())
+	stop := func() bool { return false }
+	if t.Context != nil {
+		stop = context.AfterFunc(t.Context, cancelRequest)
+	}
+	cancelTimeout := context.CancelFunc(func() {})
+	if t.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, t.Timeout)
+	}
+	cancel := func() {
+		stop()
+		cancelTimeout()
+		cancelRequest()
+	}
+
+	res, err := t.transport().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the request is done once its body has been read
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
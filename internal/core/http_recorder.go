@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// recordedExchange is the metadata of a recorded response, its body is
+// stored next to it. A request that failed without a response is recorded
+// with its error instead.
+type recordedExchange struct {
+	Method  string      `json:"method"`
+	Url     string      `json:"url"`
+	Status  int         `json:"status,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Timeout bool        `json:"timeout,omitempty"`
+	Network bool        `json:"network,omitempty"`
+}
+
+// exchangePaths returns the metadata and body paths of the nth exchange
+// for a request. Every request for the same URL is kept so retries are
+// replayed the way they happened.
+func exchangePaths(dir string, req *http.Request, n int) (string, string) {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	name := fmt.Sprintf("%s.%d", hex.EncodeToString(sum[:]), n)
+	return filepath.Join(dir, name+".json"), filepath.Join(dir, name+".body")
+}
+
+// exchangeCounter numbers the exchanges of each request.
+type exchangeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *exchangeCounter) next(req *http.Request) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	key := req.Method + " " + req.URL.String()
+	c.counts[key]++
+	return c.counts[key]
+}
+
+// HttpRecorder is an http.RoundTripper that stores every exchange in a
+// directory so the scrape can be replayed with HttpReplay.
+type HttpRecorder struct {
+	Dir       string
+	Transport http.RoundTripper
+
+	counter exchangeCounter
+}
+
+func NewHttpRecorder(dir string, transport http.RoundTripper) (*HttpRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &HttpRecorder{Dir: dir, Transport: transport}, nil
+}
+
+func (r *HttpRecorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (r *HttpRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.transport().RoundTrip(req)
+
+	// a cancelled run says nothing about the website, a request that ran out
+	// of time is recorded as a timeout
+	if errors.Is(req.Context().Err(), context.Canceled) {
+		return res, err
+	}
+
+	exchange := &recordedExchange{Method: req.Method, Url: req.URL.String()}
+	var body []byte
+	if err == nil {
+		body, err = io.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.Status = res.StatusCode
+		exchange.Header = res.Header
+	}
+	if err != nil {
+		var netErr net.Error
+		exchange.Error = err.Error()
+		exchange.Timeout = errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+		exchange.Network = isNetworkError(err)
+	}
+
+	metaPath, bodyPath := exchangePaths(r.Dir, req, r.counter.next(req))
+	content, _ := json.MarshalIndent(exchange, "", "  ")
+	if writeErr := os.WriteFile(bodyPath, body, 0644); writeErr != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", req.URL, writeErr)
+	}
+	if writeErr := os.WriteFile(metaPath, content, 0644); writeErr != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", req.URL, writeErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replayedError is a recorded request error, it is a net.Error and keeps
+// whether the original was a network error so the retry policy treats it
+// like the original.
+type replayedError struct {
+	message string
+	timeout bool
+	network bool
+}
+
+func (e *replayedError) Error() string   { return e.message }
+func (e *replayedError) Timeout() bool   { return e.timeout }
+func (e *replayedError) Temporary() bool { return e.timeout }
+
+// HttpReplay is an http.RoundTripper that serves the exchanges stored by
+// HttpRecorder without any network access. Each request gets the responses
+// in the order they were recorded, the last one is repeated once they run
+// out.
+type HttpReplay struct {
+	Dir string
+
+	counter exchangeCounter
+}
+
+func NewHttpReplay(dir string) (*HttpReplay, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	return &HttpReplay{Dir: dir}, nil
+}
+
+func (r *HttpReplay) load(req *http.Request) (*recordedExchange, []byte, error) {
+	n := r.counter.next(req)
+	for ; n > 0; n-- {
+		metaPath, bodyPath := exchangePaths(r.Dir, req, n)
+		content, err := os.ReadFile(metaPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exchange := &recordedExchange{}
+		if err := json.Unmarshal(content, exchange); err != nil {
+			return nil, nil, fmt.Errorf("invalid recording %s: %w", metaPath, err)
+		}
+		body, err := os.ReadFile(bodyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return exchange, body, nil
+	}
+
+	return nil, nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+}
+
+func (r *HttpReplay) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	exchange, body, err := r.load(req)
+	if err != nil {
+		return nil, err
+	}
+	if exchange.Error != "" {
+		return nil, &replayedError{message: exchange.Error, timeout: exchange.Timeout, network: exchange.Network || exchange.Timeout}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        exchange.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+)
+
+// TransportOptions configure how the scraper connects to the game websites.
+type TransportOptions struct {
+	// Proxy is the URL of the proxy for every request, the HTTP_PROXY and
+	// HTTPS_PROXY environment variables are used when empty
+	Proxy string
+	// CACertFile is a PEM file of certificates trusted in addition to the
+	// system ones, e.g. of a proxy intercepting TLS
+	CACertFile string
+	// InsecureSkipVerify accepts any certificate, for testing only
+	InsecureSkipVerify bool
+}
+
+// NewHttpTransport returns the transport the team providers and roster
+// loaders make their requests with.
+func NewHttpTransport(opts TransportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyUrl, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", opts.Proxy, err)
+		}
+		if !slices.Contains([]string{"http", "https", "socks5"}, proxyUrl.Scheme) || proxyUrl.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: expected http://, https:// or socks5://host:port", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if opts.CACertFile == "" && !opts.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CACertFile != "" {
+		content, err := os.ReadFile(opts.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
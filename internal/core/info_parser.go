@@ -0,0 +1,245 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContractInfo is a player's row of an INFO file.
+type ContractInfo struct {
+	// Wage and Value are amounts in the currency of the file, e.g. £1.2M is
+	// 1200000
+	Wage  float64
+	Value float64
+	// ValueUnit is the multiplier of the value's suffix, e.g. 1e6 for £1.2M,
+	// or 1 if it has none
+	ValueUnit float64
+	// Contract is the remaining contract length as given in the file
+	Contract int
+	// Values holds every column of the row as written in the file
+	Values map[string]string
+}
+
+// InfoFile is a parsed INFO file with the contract data of a club.
+type InfoFile struct {
+	// Columns is the header of the file without the Name column
+	Columns []string
+	// WageColumn, ValueColumn and ContractColumn are the headers the typed
+	// values were read from, empty if the file doesn't have them
+	WageColumn     string
+	ValueColumn    string
+	ContractColumn string
+	Players        map[string]*ContractInfo
+	// RowErrors are the rows that were skipped because they could not be read
+	RowErrors []error
+}
+
+// infoMultiWordColumns are INFO headers that contain a space and so span
+// more than one field of the header line.
+var infoMultiWordColumns = [][]string{
+	{"Mkt", "Value"},
+	{"Market", "Value"},
+	{"Contract", "Length"},
+}
+
+// joinInfoHeader joins the fields of the known multi-word headers.
+func joinInfoHeader(fields []string) []string {
+	header := []string{}
+	for i := 0; i < len(fields); i++ {
+		joined := false
+		for _, words := range infoMultiWordColumns {
+			if i+len(words) <= len(fields) && equalFoldFields(fields[i:i+len(words)], words) {
+				header = append(header, strings.Join(fields[i:i+len(words)], " "))
+				i += len(words) - 1
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			header = append(header, fields[i])
+		}
+	}
+	return header
+}
+
+func equalFoldFields(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWageColumn(col string) bool {
+	col = strings.ToLower(col)
+	return strings.HasSuffix(col, "wage") || col == "wages" || col == "salary"
+}
+
+func isValueColumn(col string) bool {
+	col = strings.ToLower(col)
+	return strings.HasSuffix(col, "value") || col == "mktval" || col == "val"
+}
+
+func isContractColumn(col string) bool {
+	col = strings.ToLower(col)
+	return strings.HasPrefix(col, "contr") || col == "yrs" || col == "years" || col == "length"
+}
+
+// ParseInfo reads an INFO file. The first column holds the player's name,
+// the wage, market value and contract length columns are recognised by
+// their header and parsed into typed values. A wage or value without any
+// digits, e.g. "-", is read as 0. A row with an invalid amount is skipped
+// and added to RowErrors, the file only fails when none of its rows can be
+// read.
+func ParseInfo(data io.Reader) (*InfoFile, error) {
+	rows, err := (&TextRosterParser{}).ParseRows(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &InfoFile{Columns: []string{}, Players: map[string]*ContractInfo{}}
+	if len(*rows) == 0 {
+		return info, nil
+	}
+
+	header := joinInfoHeader((*rows)[0])
+	for _, col := range header[1:] {
+		info.Columns = append(info.Columns, col)
+		switch {
+		case info.WageColumn == "" && isWageColumn(col):
+			info.WageColumn = col
+		case info.ValueColumn == "" && isValueColumn(col):
+			info.ValueColumn = col
+		case info.ContractColumn == "" && isContractColumn(col):
+			info.ContractColumn = col
+		}
+	}
+
+	for rowNum, row := range (*rows)[1:] {
+		contract, err := parseContractRow(info, row)
+		if err != nil {
+			info.RowErrors = append(info.RowErrors, &RowError{Row: rowNum + 1, Err: err})
+			continue
+		}
+		info.Players[strings.ToLower(row[0])] = contract
+	}
+
+	if len(info.Players) == 0 && len(info.RowErrors) > 0 {
+		return nil, fmt.Errorf("no readable rows, %s", info.RowErrors[0])
+	}
+
+	return info, nil
+}
+
+// parseContractRow reads a player's row of an INFO file.
+func parseContractRow(info *InfoFile, row []string) (*ContractInfo, error) {
+	contract := &ContractInfo{ValueUnit: 1, Values: map[string]string{}}
+	for i, val := range row[1:] {
+		if i >= len(info.Columns) {
+			break
+		}
+		col := info.Columns[i]
+		contract.Values[col] = val
+
+		switch {
+		case (col == info.WageColumn || col == info.ValueColumn) && strings.ContainsFunc(val, unicode.IsDigit):
+			amount, unit, err := parseMoney(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %w", col, err)
+			}
+			if col == info.WageColumn {
+				contract.Wage = amount
+			} else {
+				contract.Value = amount
+				contract.ValueUnit = unit
+			}
+		case col == info.ContractColumn:
+			contract.Contract = parseLeadingInt(val)
+		}
+	}
+	return contract, nil
+}
+
+// ExtraColumns returns the columns besides wage, value and contract.
+func (f *InfoFile) ExtraColumns() []string {
+	cols := []string{}
+	for _, col := range f.Columns {
+		if col != f.WageColumn && col != f.ValueColumn && col != f.ContractColumn {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// Player returns the contract data of the named player, or nil.
+func (f *InfoFile) Player(name string) *ContractInfo {
+	if f == nil {
+		return nil
+	}
+	return f.Players[strings.ToLower(name)]
+}
+
+// moneyUnits are the unit suffixes of money amounts, longest first.
+var moneyUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"bn", 1e9},
+	{"mn", 1e6},
+	{"b", 1e9},
+	{"m", 1e6},
+	{"k", 1e3},
+}
+
+// parseMoney parses an amount such as 12,000, £1.2M, $500K or 2.5bn€ into
+// currency units, ignoring the currency symbol. It also returns the
+// multiplier of the amount's suffix, 1 if it has none.
+func parseMoney(value string) (float64, float64, error) {
+	isNumber := func(r rune) bool { return unicode.IsDigit(r) || r == '.' || r == ',' }
+	start := strings.IndexFunc(value, isNumber)
+	if start < 0 {
+		return 0, 0, fmt.Errorf("no amount in %q", value)
+	}
+	end := start + strings.IndexFunc(value[start:]+" ", func(r rune) bool { return !isNumber(r) })
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value[start:end], ",", ""), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	multiplier := 1.0
+	rest := strings.TrimSpace(value[end:])
+	for _, unit := range moneyUnits {
+		n := len(unit.suffix)
+		if len(rest) < n || !strings.EqualFold(rest[:n], unit.suffix) {
+			continue
+		}
+		// a suffix followed by a letter is part of a currency, e.g. BTC
+		if next, _ := utf8.DecodeRuneInString(rest[n:]); unicode.IsLetter(next) {
+			continue
+		}
+		multiplier = unit.multiplier
+		break
+	}
+
+	return amount * multiplier, multiplier, nil
+}
+
+// parseLeadingInt returns the number at the start of the value, e.g. 3 for
+// "3yrs", or 0 if there is none.
+func parseLeadingInt(value string) int {
+	end := strings.IndexFunc(value, func(r rune) bool { return !unicode.IsDigit(r) })
+	if end < 0 {
+		end = len(value)
+	}
+	num, _ := strconv.Atoi(value[:end])
+	return num
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value   string
+		amount  float64
+		unit    float64
+		wantErr bool
+	}{
+		{value: "12,000", amount: 12000, unit: 1},
+		{value: "12.5", amount: 12.5, unit: 1},
+		{value: "£45,000", amount: 45000, unit: 1},
+		{value: "£1.2M", amount: 1200000, unit: 1e6},
+		{value: "$500K", amount: 500000, unit: 1e3},
+		{value: "12k€", amount: 12000, unit: 1e3},
+		{value: "2.5bn€", amount: 2.5e9, unit: 1e9},
+		{value: "3mn", amount: 3e6, unit: 1e6},
+		{value: "1B", amount: 1e9, unit: 1e9},
+		{value: "1.2 M EUR", amount: 1200000, unit: 1e6},
+		{value: "1,000 BTC", amount: 1000, unit: 1},
+		{value: "-", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "£1.2.3M", wantErr: true},
+		{value: "£1..2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			amount, unit, err := parseMoney(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want an error", amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if amount != tt.amount || unit != tt.unit {
+				t.Errorf("got %v with unit %v, want %v with unit %v", amount, unit, tt.amount, tt.unit)
+			}
+		})
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	const content = `Name      Wage      Mkt Value  Contract  Loan
+-------------------------------------------------
+A_One     £45,000   £18.5M     2yrs      -
+B_Two     12k€      12.5       3         ARS
+C_Three   -         -          1         -
+`
+
+	info, err := ParseInfo(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.WageColumn != "Wage" || info.ValueColumn != "Mkt Value" || info.ContractColumn != "Contract" {
+		t.Errorf("got columns %q, %q and %q", info.WageColumn, info.ValueColumn, info.ContractColumn)
+	}
+	if cols := info.ExtraColumns(); len(cols) != 1 || cols[0] != "Loan" {
+		t.Errorf("got extra columns %v, want [Loan]", cols)
+	}
+
+	tests := []struct {
+		name      string
+		wage      float64
+		value     float64
+		valueUnit float64
+		contract  int
+	}{
+		{name: "A_One", wage: 45000, value: 18500000, valueUnit: 1e6, contract: 2},
+		{name: "b_two", wage: 12000, value: 12.5, valueUnit: 1, contract: 3},
+		{name: "C_Three", valueUnit: 1, contract: 1},
+	}
+	for _, tt := range tests {
+		p := info.Player(tt.name)
+		if p == nil {
+			t.Errorf("%s: not found", tt.name)
+			continue
+		}
+		if p.Wage != tt.wage || p.Value != tt.value || p.ValueUnit != tt.valueUnit || p.Contract != tt.contract {
+			t.Errorf("%s: got %+v", tt.name, p)
+		}
+	}
+}
+
+func TestParseInfoMalformedAmount(t *testing.T) {
+	const content = "Name Wage Value\nA_One £45,000 £1.2.3M\nB_Two £10,000 £2M\n"
+	info, err := ParseInfo(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Player("A_One") != nil || info.Player("B_Two") == nil {
+		t.Error("got the malformed row read or the valid row skipped")
+	}
+
+	var rowErr *RowError
+	if len(info.RowErrors) != 1 || !errors.As(info.RowErrors[0], &rowErr) || rowErr.Row != 1 {
+		t.Errorf("got row errors %v, want row 1", info.RowErrors)
+	}
+}
+
+func TestParseInfoNoReadableRows(t *testing.T) {
+	const content = "Name Wage Value\nA_One £45,000 £1.2.3M\n"
+	if _, err := ParseInfo(strings.NewReader(content)); err == nil {
+		t.Error("got no error for a file without readable rows")
+	}
+}
+
+func TestApplyInfoValueInMillions(t *testing.T) {
+	const content = "Name Wage Value\nA_One £45,000 £500K\nB_Two £10,000 12.5\n"
+	info, err := ParseInfo(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &RosterFile{Players: []*Player{{Name: "A_One"}, {Name: "B_Two"}}, Info: info}
+	r.applyInfo()
+	if r.Players[0].Value != 0.5 || r.Players[1].Value != 12.5 {
+		t.Errorf("got values %v and %v, want 0.5 and 12.5", r.Players[0].Value, r.Players[1].Value)
+	}
+}
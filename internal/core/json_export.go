@@ -0,0 +1,86 @@
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/fatih/color"
+)
+
+// PlayerRecord is a player together with the club it plays for.
+type PlayerRecord struct {
+	Team   string `json:"team"`
+	Code   string `json:"code"`
+	League string `json:"league"`
+	*Player
+	Computed map[string]float64 `json:"computed,omitempty"`
+
+	roster *RosterFile
+}
+
+type jsonExport struct {
+	ScrapeMeta
+	Players []PlayerRecord `json:"players"`
+}
+
+// PlayerRecords flattens the rosters into one record per player.
+func PlayerRecords(rosters []*RosterFile) []PlayerRecord {
+	return computedPlayerRecords(rosters, nil)
+}
+
+// computedPlayerRecords flattens the rosters into one record per player
+// with the values of the given computed columns.
+func computedPlayerRecords(rosters []*RosterFile, cols []*ComputedColumn) []PlayerRecord {
+	records := []PlayerRecord{}
+	for _, r := range rosters {
+		for _, p := range r.Players {
+			records = append(records, PlayerRecord{Team: r.Name, Code: r.Code, League: r.League, Player: p, Computed: computedValues(r, p, cols), roster: r})
+		}
+	}
+	return records
+}
+
+// ExportToJson writes the scrape metadata and every player to a single JSON
+// document.
+func ExportToJson(rosters []*RosterFile, opts ExportOptions) (string, error) {
+	file, err := createExportFile(opts.OutputDir, opts.FileNamePrefix, opts.Meta.Timestamp, "json")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	export := jsonExport{ScrapeMeta: opts.Meta, Players: computedPlayerRecords(rosters, opts.ComputedColumns)}
+	sortPlayerRecords(export.Players, opts.Sort)
+	if export.Errors == nil {
+		export.Errors = []string{}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return "", err
+	}
+
+	color.Blue("Finished\t\t ... Players=%s, Clubs=%d\n", formatPlayerCount(rosters), countClubs(rosters))
+	return exportPath(file), nil
+}
+
+// ExportToNdjson writes one JSON object per player, one per line.
+func ExportToNdjson(rosters []*RosterFile, opts ExportOptions) (string, error) {
+	file, err := createExportFile(opts.OutputDir, opts.FileNamePrefix, opts.Meta.Timestamp, "ndjson")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	records := computedPlayerRecords(rosters, opts.ComputedColumns)
+	sortPlayerRecords(records, opts.Sort)
+	encoder := json.NewEncoder(file)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return "", err
+		}
+	}
+
+	color.Blue("Finished\t\t ... Players=%s, Clubs=%d\n", formatPlayerCount(rosters), countClubs(rosters))
+	return exportPath(file), nil
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type ClubStatus string
+
+const (
+	ClubLoaded  ClubStatus = "loaded"
+	ClubFailed  ClubStatus = "failed"
+	ClubSkipped ClubStatus = "skipped"
+)
+
+// ManifestClub is the load result of a single club.
+type ManifestClub struct {
+	Code       string              `json:"code"`
+	Name       string              `json:"name"`
+	League     string              `json:"league"`
+	Status     ClubStatus          `json:"status"`
+	Attempts   int                 `json:"attempts"`
+	DurationMs int64               `json:"durationMs"`
+	Players    int                 `json:"players"`
+	Squads     map[Squad]int       `json:"squads,omitempty"`
+	Files      map[FileKind]string `json:"sha256,omitempty"`
+}
+
+// Manifest describes a scrape run and is written next to its export.
+type Manifest struct {
+	Version    string         `json:"version"`
+	Game       string         `json:"game"`
+	TeamsUrl   string         `json:"teamsUrl"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Options    ScraperOptions `json:"options"`
+	ExportFile string         `json:"exportFile"`
+	Clubs      int            `json:"clubs"`
+	Loaded     int            `json:"loaded"`
+	Failed     int            `json:"failed"`
+	Players    int            `json:"players"`
+	Squads     map[Squad]int  `json:"squads"`
+	Results    []ManifestClub `json:"results"`
+	Errors     []interface{}  `json:"errors"`
+}
+
+// NewManifest summarises the rosters and errors of a run. A club is failed
+// when its roster file could not be loaded, not just some of its rows, and
+// skipped when it was never attempted, e.g. because the run was cancelled.
+func NewManifest(rosters []*RosterFile, errs []error, opts ScraperOptions, meta ScrapeMeta, exportFile string) *Manifest {
+	failed := map[string]bool{}
+	for _, e := range errs {
+		// a skipped row leaves the rest of the roster loaded
+		var scrapeErr *ScrapeError
+		var rowErr *RowError
+		if errors.As(e, &scrapeErr) && scrapeErr.Kind == FileRoster && !errors.As(e, &rowErr) {
+			failed[scrapeErr.Code] = true
+		}
+	}
+
+	m := &Manifest{
+		Version:    meta.Version,
+		Game:       meta.Game,
+		TeamsUrl:   meta.SourceUrl,
+		Timestamp:  meta.Timestamp,
+		Options:    opts,
+		ExportFile: exportFile,
+		Clubs:      len(rosters),
+		Squads:     map[Squad]int{},
+		Results:    []ManifestClub{},
+		Errors:     errorEntries(errs),
+	}
+
+	for _, r := range rosters {
+		club := ManifestClub{
+			Code:       r.Code,
+			Name:       r.Name,
+			League:     r.League,
+			Status:     ClubSkipped,
+			Attempts:   r.Attempts,
+			DurationMs: r.Duration.Milliseconds(),
+			Players:    len(r.Players),
+			Files:      r.Checksums,
+		}
+
+		if failed[r.Code] {
+			club.Status = ClubFailed
+			club.Players = 0
+			m.Failed++
+		} else if r.Players != nil {
+			club.Status = ClubLoaded
+			club.Squads = squadCounts([]*RosterFile{r})
+			m.Loaded++
+			m.Players += club.Players
+			for s, n := range club.Squads {
+				m.Squads[s] += n
+			}
+		}
+
+		m.Results = append(m.Results, club)
+	}
+
+	return m
+}
+
+// WriteManifest writes the manifest to <prefix><timestamp>.manifest.json in
+// the output directory and returns the path of the file.
+func WriteManifest(m *Manifest, outputDir string, fileNamePrefix string) (string, error) {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	manifestPath := path.Join(outputDir, fmt.Sprintf("%s%d.manifest.json", fileNamePrefix, m.Timestamp.Unix()))
+	if err := os.WriteFile(manifestPath, content, 0644); err != nil {
+		return "", err
+	}
+
+	color.Green("Manifest file\t\t ... %s.", manifestPath)
+	return manifestPath, nil
+}
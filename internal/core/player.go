@@ -0,0 +1,221 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Squad tells the first team players of a club apart from its academy and
+// youth players.
+type Squad string
+
+const (
+	SquadSenior  Squad = "Senior"
+	SquadAcademy Squad = "Academy"
+	SquadYouth   Squad = "Youth"
+)
+
+// Squads lists the squads in export order.
+var Squads = []Squad{SquadSenior, SquadAcademy, SquadYouth}
+
+// ParseSquad returns the squad with the given name, ignoring case.
+func ParseSquad(name string) (Squad, error) {
+	for _, s := range Squads {
+		if strings.EqualFold(string(s), strings.TrimSpace(name)) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown squad %q, expected one of Senior, Academy or Youth", name)
+}
+
+// ParseSquads parses a list of squad names.
+func ParseSquads(names []string) ([]Squad, error) {
+	squads := []Squad{}
+	for _, name := range names {
+		s, err := ParseSquad(name)
+		if err != nil {
+			return nil, err
+		}
+		squads = append(squads, s)
+	}
+	return squads, nil
+}
+
+// Player is a single roster entry with typed skill, ability and stat values.
+// Columns found in a roster header that have no dedicated field are kept in
+// Extra so nothing is lost when a league uses a non-standard layout. Wage,
+// Value (in millions) and Contract come from the club's INFO file.
+type Player struct {
+	Name     string            `json:"name"`
+	Age      int               `json:"age"`
+	Nat      string            `json:"nat"`
+	Prs      string            `json:"prs"`
+	St       int               `json:"st"`
+	Tk       int               `json:"tk"`
+	Ps       int               `json:"ps"`
+	Sh       int               `json:"sh"`
+	Ag       int               `json:"ag"`
+	KAb      int               `json:"kab"`
+	TAb      int               `json:"tab"`
+	PAb      int               `json:"pab"`
+	SAb      int               `json:"sab"`
+	Gam      int               `json:"gam"`
+	Sub      int               `json:"sub"`
+	Min      int               `json:"min"`
+	Mom      int               `json:"mom"`
+	Sav      int               `json:"sav"`
+	Con      int               `json:"con"`
+	Ktk      int               `json:"ktk"`
+	Kps      int               `json:"kps"`
+	Sht      int               `json:"sht"`
+	Gls      int               `json:"gls"`
+	Ass      int               `json:"ass"`
+	DP       int               `json:"dp"`
+	Inj      int               `json:"inj"`
+	Sus      int               `json:"sus"`
+	Fit      int               `json:"fit"`
+	Wage     float64           `json:"wage,omitempty"`
+	Value    float64           `json:"value,omitempty"`
+	Contract int               `json:"contract,omitempty"`
+	Squad    Squad             `json:"squad"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	// Info holds the INFO columns other than wage, value and contract
+	Info map[string]string `json:"info,omitempty"`
+}
+
+// hasInfo reports whether the player has a row in the club's INFO file, the
+// wage, value and contract of a player without one are unknown rather than
+// 0.
+func (p *Player) hasInfo() bool {
+	return p.Info != nil
+}
+
+func (p *Player) stringFields() map[string]*string {
+	return map[string]*string{
+		"Name": &p.Name,
+		"Nat":  &p.Nat,
+		"Prs":  &p.Prs,
+	}
+}
+
+func (p *Player) intFields() map[string]*int {
+	return map[string]*int{
+		"Age": &p.Age,
+		"St":  &p.St,
+		"Tk":  &p.Tk,
+		"Ps":  &p.Ps,
+		"Sh":  &p.Sh,
+		"Ag":  &p.Ag,
+		"KAb": &p.KAb,
+		"TAb": &p.TAb,
+		"PAb": &p.PAb,
+		"SAb": &p.SAb,
+		"Gam": &p.Gam,
+		"Sub": &p.Sub,
+		"Min": &p.Min,
+		"Mom": &p.Mom,
+		"Sav": &p.Sav,
+		"Con": &p.Con,
+		"Ktk": &p.Ktk,
+		"Kps": &p.Kps,
+		"Sht": &p.Sht,
+		"Gls": &p.Gls,
+		"Ass": &p.Ass,
+		"DP":  &p.DP,
+		"Inj": &p.Inj,
+		"Sus": &p.Sus,
+		"Fit": &p.Fit,
+	}
+}
+
+// Set assigns a raw roster value to the field matching the column name.
+func (p *Player) Set(col string, value string) error {
+	if f, ok := p.stringFields()[col]; ok {
+		*f = value
+		return nil
+	}
+
+	if f, ok := p.intFields()[col]; ok {
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*f = val
+		return nil
+	}
+
+	if p.Extra == nil {
+		p.Extra = map[string]string{}
+	}
+	p.Extra[col] = value
+	return nil
+}
+
+// Get returns the value of the column as it would appear in a roster file.
+func (p *Player) Get(col string) (string, bool) {
+	if f, ok := p.stringFields()[col]; ok {
+		return *f, true
+	}
+
+	if f, ok := p.intFields()[col]; ok {
+		return strconv.Itoa(*f), true
+	}
+
+	val, ok := p.Extra[col]
+	return val, ok
+}
+
+// Int returns the integer value of the column, or 0 if it is not numeric.
+func (p *Player) Int(col string) int {
+	if f, ok := p.intFields()[col]; ok {
+		return *f
+	}
+
+	val, _ := strconv.Atoi(p.Extra[col])
+	return val
+}
+
+// Values returns the player's values in the order of the given columns.
+func (p *Player) Values(cols []string) []string {
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		values[i], _ = p.Get(col)
+	}
+	return values
+}
+
+// Side returns the sides encoded in the suffix of the player's name, e.g.
+// "L" for A_Jones_L or "RC" for B_Brown_RC, and "" when there is none.
+func (p *Player) Side() string {
+	i := strings.LastIndex(p.Name, "_")
+	if i < 0 {
+		return ""
+	}
+
+	suffix := p.Name[i+1:]
+	if suffix == "" || len(suffix) > 3 || strings.Trim(suffix, "LRC") != "" {
+		return ""
+	}
+	return suffix
+}
+
+func normalizeColumnName(col string) string {
+	for _, known := range (&Player{}).knownColumns() {
+		if strings.EqualFold(col, known) {
+			return known
+		}
+	}
+	return col
+}
+
+func (p *Player) knownColumns() []string {
+	cols := []string{}
+	for k := range p.stringFields() {
+		cols = append(cols, k)
+	}
+	for k := range p.intFields() {
+		cols = append(cols, k)
+	}
+	return cols
+}
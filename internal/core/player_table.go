@@ -0,0 +1,295 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// mergeColumns returns the superset of every roster's header. Columns that
+// only appear in some rosters are placed after the column that precedes
+// them in the first roster they are found in.
+func mergeColumns(rosters []*RosterFile) []string {
+	cols := []string{}
+	for _, r := range rosters {
+		prev := -1
+		for _, c := range r.Columns {
+			if idx := slices.Index(cols, c); idx > -1 {
+				prev = idx
+			} else {
+				prev++
+				cols = slices.Insert(cols, prev, c)
+			}
+		}
+	}
+	return cols
+}
+
+// commonColumns returns the header shared by the most rosters.
+func commonColumns(rosters []*RosterFile) []string {
+	counts := map[string]int{}
+	var common []string
+	for _, r := range rosters {
+		if r.Players == nil {
+			continue
+		}
+		key := strings.Join(r.Columns, " ")
+		counts[key]++
+		if common == nil || counts[key] > counts[strings.Join(common, " ")] {
+			common = r.Columns
+		}
+	}
+	return common
+}
+
+// warnHeaderMismatches prints the rosters whose header differs from the
+// header used by most other rosters.
+func warnHeaderMismatches(rosters []*RosterFile) {
+	common := commonColumns(rosters)
+	mismatches := []string{}
+	for _, r := range rosters {
+		if r.Players == nil || slices.Equal(r.Columns, common) {
+			continue
+		}
+
+		diff := []string{}
+		if missing := columnsNotIn(common, r.Columns); len(missing) > 0 {
+			diff = append(diff, fmt.Sprintf("missing %s", strings.Join(missing, ", ")))
+		}
+		if extra := columnsNotIn(r.Columns, common); len(extra) > 0 {
+			diff = append(diff, fmt.Sprintf("extra %s", strings.Join(extra, ", ")))
+		}
+		if len(diff) == 0 {
+			diff = append(diff, "different column order")
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%s (%s)", r.Code, strings.Join(diff, "; ")))
+	}
+
+	if len(mismatches) > 0 {
+		color.Yellow("Roster headers differ\t ... %s", strings.Join(mismatches, ", "))
+	}
+}
+
+func columnsNotIn(cols []string, other []string) []string {
+	result := []string{}
+	for _, c := range cols {
+		if !slices.Contains(other, c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func countClubs(rosters []*RosterFile) int {
+	count := 0
+	for _, r := range rosters {
+		if r.Players != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// squadCounts returns the number of players of each squad.
+func squadCounts(rosters []*RosterFile) map[Squad]int {
+	counts := map[Squad]int{}
+	for _, r := range rosters {
+		for _, p := range r.Players {
+			counts[p.Squad]++
+		}
+	}
+	return counts
+}
+
+// formatPlayerCount formats the number of players, followed by the count
+// of each squad when not all of them are senior players, e.g.
+// "25 (Senior=20, Academy=5)".
+func formatPlayerCount(rosters []*RosterFile) string {
+	counts := squadCounts(rosters)
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if counts[SquadSenior] == total {
+		return strconv.Itoa(total)
+	}
+
+	parts := []string{}
+	for _, s := range Squads {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", s, counts[s]))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", total, strings.Join(parts, ", "))
+}
+
+// infoColumns returns the INFO headers of the rosters: Wage and Mkt Value,
+// Contract if any INFO file has a contract length and then the other INFO
+// columns not already among the headers.
+func infoColumns(rosters []*RosterFile, headers []string) []string {
+	cols := []string{}
+	hasContract := false
+	extra := []string{}
+	for _, r := range rosters {
+		if r.Info == nil {
+			continue
+		}
+		if len(cols) == 0 {
+			cols = append(cols, "Wage", "Mkt Value")
+		}
+		hasContract = hasContract || r.Info.ContractColumn != ""
+		for _, col := range r.Info.ExtraColumns() {
+			if !slices.Contains(extra, col) && !slices.Contains(headers, col) {
+				extra = append(extra, col)
+			}
+		}
+	}
+
+	if hasContract {
+		cols = append(cols, "Contract")
+	}
+	return append(cols, extra...)
+}
+
+// tableColumns returns the export headers and the computed columns among
+// them. A computed column is left out when no roster has the columns its
+// expression needs.
+func tableColumns(rosters []*RosterFile, computed []*ComputedColumn) ([]string, map[string]*ComputedColumn) {
+	headers := append([]string{"Team", "Code", "League", "Squad"}, mergeColumns(rosters)...)
+
+	headers = append(headers, infoColumns(rosters, headers)...)
+
+	active := map[string]*ComputedColumn{}
+	for _, c := range computedColumns(computed) {
+		missing := c.missingColumns(headers)
+		isDefault := slices.Contains(defaultComputedColumns, c)
+		if slices.Contains(headers, c.Name) {
+			if !isDefault {
+				color.Yellow("Computed column skipped\t ... %s (column already exists)", c.Name)
+			}
+			continue
+		}
+		if len(missing) > 0 {
+			if !isDefault {
+				color.Yellow("Computed column skipped\t ... %s (missing %s)", c.Name, strings.Join(missing, ", "))
+			}
+			continue
+		}
+
+		if idx := slices.Index(headers, c.After); c.After != "" && idx > -1 {
+			headers = slices.Insert(headers, idx+1, c.Name)
+		} else {
+			headers = append(headers, c.Name)
+		}
+		active[c.Name] = c
+	}
+
+	return headers, active
+}
+
+// selectColumns returns the requested columns in the requested order, or
+// every header when none are requested.
+func selectColumns(headers []string, cols []string) []string {
+	if len(cols) == 0 {
+		return headers
+	}
+
+	selected := []string{}
+	unknown := []string{}
+	for _, col := range cols {
+		idx := slices.IndexFunc(headers, func(h string) bool {
+			return strings.EqualFold(h, col) || h == exportColumnName(col)
+		})
+		if idx < 0 {
+			unknown = append(unknown, col)
+		} else if !slices.Contains(selected, headers[idx]) {
+			selected = append(selected, headers[idx])
+		}
+	}
+
+	if len(unknown) > 0 {
+		color.Yellow("Unknown columns\t\t ... %s", strings.Join(unknown, ", "))
+	}
+	return selected
+}
+
+// buildPlayerTable flattens the rosters into export rows, sorted and with
+// the columns selected by the options. Columns are mapped by name onto the
+// superset of all roster headers, leaving a blank value where a roster
+// doesn't have the column. Computed columns are written as spreadsheet
+// formulas when UseExcelFormulas is set and every column they read is
+// exported, and are returned by header.
+func buildPlayerTable(rosters []*RosterFile, opts ExportOptions) ([]string, [][]string, map[string]*ComputedColumn) {
+	allHeaders, active := tableColumns(rosters, opts.ComputedColumns)
+	headers := selectColumns(allHeaders, opts.Columns)
+	cellRef := func(col string) string {
+		return fmt.Sprintf("INDEX(%s:%[1]s, ROW())", getLetterForCol(slices.Index(headers, exportColumnName(col))+1))
+	}
+
+	computed := map[string]*ComputedColumn{}
+	activeCols := []*ComputedColumn{}
+	for name, c := range active {
+		if slices.Contains(headers, name) {
+			computed[name] = c
+		}
+		activeCols = append(activeCols, c)
+	}
+
+	// computed values of every active column so they can be sorted on
+	players := computedPlayerRecords(rosters, activeCols)
+	sortPlayerRecords(players, opts.Sort)
+
+	records := [][]string{}
+	for _, rec := range players {
+		r, p := rec.roster, rec.Player
+		fields := make([]string, len(headers))
+		for i, h := range headers {
+			if c, ok := computed[h]; ok {
+				if !c.available(r) {
+					continue
+				}
+				if opts.UseExcelFormulas && len(c.missingColumns(headers)) == 0 {
+					fields[i] = fmt.Sprintf("=IFERROR(%s, 0)", c.Expr.Formula(cellRef))
+				} else if val, ok := rec.Computed[h]; ok {
+					fields[i] = formatComputedValue(val)
+				}
+				continue
+			}
+
+			switch h {
+			case "Team":
+				fields[i] = r.Name
+			case "Code":
+				fields[i] = r.Code
+			case "League":
+				fields[i] = r.League
+			case "Squad":
+				fields[i] = string(p.Squad)
+			case "Wage":
+				if r.Info != nil && p.hasInfo() {
+					fields[i] = formatMoney(p.Wage)
+				}
+			case "Mkt Value":
+				if r.Info != nil && p.hasInfo() {
+					fields[i] = formatMoney(p.Value)
+				}
+			case "Contract":
+				if r.Info != nil && r.Info.ContractColumn != "" && p.hasInfo() {
+					fields[i] = strconv.Itoa(p.Contract)
+				}
+			default:
+				if slices.Contains(r.Columns, h) {
+					fields[i], _ = p.Get(h)
+				} else if val, ok := p.Info[h]; ok {
+					fields[i] = val
+				}
+			}
+		}
+		records = append(records, fields)
+	}
+
+	return headers, records, computed
+}
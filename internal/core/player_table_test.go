@@ -0,0 +1,33 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildPlayerTableWithoutInfoRow(t *testing.T) {
+	info, err := ParseInfo(strings.NewReader("Name Wage Value Contract\nA_One £45,000 £18.5M 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &RosterFile{
+		Code:    "ARS",
+		Columns: []string{"Name", "Age"},
+		Players: []*Player{{Name: "A_One", Age: 20}, {Name: "B_Two", Age: 21}},
+		Info:    info,
+	}
+	r.applyInfo()
+
+	headers, records, _ := buildPlayerTable([]*RosterFile{r}, ExportOptions{Columns: []string{"Name", "Wage", "Mkt Value", "Contract"}})
+	if !slices.Equal(headers, []string{"Name", "Wage", "Mkt Value", "Contract"}) {
+		t.Fatalf("got headers %v", headers)
+	}
+
+	want := [][]string{{"A_One", "45000", "18.5", "2"}, {"B_Two", "", "", ""}}
+	for i, rec := range records {
+		if !slices.Equal(rec, want[i]) {
+			t.Errorf("got row %v, want %v", rec, want[i])
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent identifies the scraper to the game websites.
+const DefaultUserAgent = "player-scraper"
+
+// ErrDisallowedByRobots is returned for a request the website's robots.txt
+// does not allow, it is not retried.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// tokenBucket allows a steady rate of requests to a host, with bursts of up
+// to its size.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// PoliteTransport is an http.RoundTripper that identifies the scraper with
+// its User-Agent and limits the requests made to each host. The team
+// providers and roster loaders share it so the limits apply to the scrape as
+// a whole.
+type PoliteTransport struct {
+	// UserAgent replaces the User-Agent of every request, DefaultUserAgent
+	// when empty
+	UserAgent string
+	// RequestsPerSecond is the number of requests each host gets, no limit
+	// when zero
+	RequestsPerSecond float64
+	// Burst is the number of requests a host can get at once after being idle,
+	// at least 1
+	Burst int
+	// RespectRobots checks every request against the host's robots.txt
+	RespectRobots bool
+	Transport     http.RoundTripper
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	robots  map[string]*robotsEntry
+}
+
+// robotsEntry is the robots.txt of a host, loaded by the first request for
+// the host while the others wait on ready.
+type robotsEntry struct {
+	ready chan struct{}
+	rules *RobotsRules
+}
+
+func (t *PoliteTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (t *PoliteTransport) userAgent() string {
+	if t.UserAgent != "" {
+		return t.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+// reserve takes a token from the host's bucket and returns how long to wait
+// before it may be used.
+func (t *PoliteTransport) reserve(host string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.buckets == nil {
+		t.buckets = map[string]*tokenBucket{}
+	}
+	size := float64(max(t.Burst, 1))
+	now := time.Now()
+	bucket, ok := t.buckets[host]
+	if !ok {
+		bucket = &tokenBucket{tokens: size, last: now}
+		t.buckets[host] = bucket
+	}
+
+	bucket.tokens = min(size, bucket.tokens+now.Sub(bucket.last).Seconds()*t.RequestsPerSecond)
+	bucket.last = now
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / t.RequestsPerSecond * float64(time.Second))
+}
+
+// wait blocks until the host may get another request.
+func (t *PoliteTransport) wait(ctx context.Context, host string) error {
+	if t.RequestsPerSecond <= 0 {
+		return ctx.Err()
+	}
+	if d := t.reserve(host); d > 0 {
+		return sleep(ctx, d)
+	}
+	return ctx.Err()
+}
+
+// robotsRules returns the robots.txt rules of the request's host, loading
+// them on first use. A robots.txt that can't be loaded allows everything.
+func (t *PoliteTransport) robotsRules(req *http.Request) *RobotsRules {
+	key := req.URL.Scheme + "://" + req.URL.Host
+
+	t.mu.Lock()
+	if t.robots == nil {
+		t.robots = map[string]*robotsEntry{}
+	}
+	entry, ok := t.robots[key]
+	if !ok {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		t.robots[key] = entry
+	}
+	t.mu.Unlock()
+
+	if ok {
+		select {
+		case <-entry.ready:
+			return entry.rules
+		case <-req.Context().Done():
+			return nil
+		}
+	}
+
+	defer close(entry.ready)
+	robotsReq, err := http.NewRequestWithContext(req.Context(), "GET", key+"/robots.txt", nil)
+	if err != nil {
+		return nil
+	}
+	res, err := t.send(robotsReq)
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		entry.rules, _ = ParseRobots(res.Body, t.userAgent())
+	}
+	return entry.rules
+}
+
+// send makes the request with the User-Agent once the rate limit allows.
+func (t *PoliteTransport) send(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent())
+	return t.transport().RoundTrip(req)
+}
+
+func (t *PoliteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.RespectRobots && !t.robotsRules(req).Allowed(req.URL.RequestURI()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrDisallowedByRobots
+	}
+
+	return t.send(req)
+}
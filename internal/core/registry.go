@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderOptions configure how a team provider accesses the game website.
+type ProviderOptions struct {
+	// Transport makes the requests of the provider, http.DefaultTransport
+	// when nil
+	Transport http.RoundTripper
+	// Context stops the requests in flight when it is done
+	Context context.Context
+	// RequestTimeout limits each request, no limit when zero
+	RequestTimeout time.Duration
+}
+
+// HttpTransport returns the transport for the provider's collector, it
+// makes the requests through Transport within the context and timeout.
+func (o ProviderOptions) HttpTransport() http.RoundTripper {
+	return &ContextTransport{Context: o.Context, Timeout: o.RequestTimeout, Transport: o.Transport}
+}
+
+// Game describes an ESMS game the scraper knows how to load rosters for.
+type Game struct {
+	Name            string
+	Title           string
+	TeamsUrl        string
+	NewTeamProvider func(url string, opts ProviderOptions) TeamProvider
+}
+
+var (
+	gamesMu sync.RWMutex
+	games   = map[string]*Game{}
+)
+
+// RegisterGame makes a game available by name. It panics if a game with the
+// same name is registered twice.
+func RegisterGame(game *Game) {
+	gamesMu.Lock()
+	defer gamesMu.Unlock()
+
+	name := strings.ToLower(game.Name)
+	if _, exists := games[name]; exists {
+		panic(fmt.Sprintf("game already registered: %s", name))
+	}
+	games[name] = game
+}
+
+func GetGame(name string) (*Game, error) {
+	gamesMu.RLock()
+	defer gamesMu.RUnlock()
+
+	game, ok := games[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown game %q (available: %s)", name, strings.Join(gameNames(), ", "))
+	}
+	return game, nil
+}
+
+func GameNames() []string {
+	gamesMu.RLock()
+	defer gamesMu.RUnlock()
+
+	return gameNames()
+}
+
+func gameNames() []string {
+	names := []string{}
+	for name := range games {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// HttpStatusError is returned when a file download responds with a status
+// other than 200.
+type HttpStatusError struct {
+	Url        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *HttpStatusError) Error() string {
+	return fmt.Sprintf("file download failed: %s - %s", e.Url, e.Status)
+}
+
+func newHttpStatusError(url string, statusCode int, header http.Header) *HttpStatusError {
+	return &HttpStatusError{
+		Url:        url,
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		RetryAfter: parseRetryAfter(header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// RetryPolicy decides whether a failed download is attempted again and how
+// long to wait before doing so.
+type RetryPolicy struct {
+	MaxAttempts          int
+	BaseDelay            time.Duration
+	MaxDelay             time.Duration
+	Jitter               float64
+	RetryableStatusCodes []int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+	RetryableStatusCodes: []int{
+		http.StatusRequestTimeout,
+		http.StatusTooEarly,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// ShouldRetry reports whether another attempt should be made after the
+// given attempt failed. Only network errors and retryable status codes are
+// retried, anything else (e.g. a 404 or a malformed roster) fails fast.
+func (p RetryPolicy) ShouldRetry(err error, attempt int) bool {
+	if err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	// a request timeout also matches context.DeadlineExceeded so only a
+	// cancellation stops here, the loaders check the context of the run
+	// before every further attempt
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// robots.txt won't allow the file on the next attempt either
+	if errors.Is(err, ErrDisallowedByRobots) {
+		return false
+	}
+
+	var statusErr *HttpStatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.RetryableStatusCodes, statusErr.StatusCode)
+	}
+
+	return isNetworkError(err)
+}
+
+// isNetworkError reports whether the request failed on the way to or from
+// the server, e.g. a refused connection or a timeout, rather than before it
+// was sent, e.g. an invalid certificate or an unsupported scheme.
+func isNetworkError(err error) bool {
+	var replayed *replayedError
+	if errors.As(err, &replayed) {
+		return replayed.network
+	}
+
+	// the client wraps every error in a *url.Error, which is a net.Error
+	// itself, so only the error it wraps tells them apart
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// Delay returns how long to wait before the next attempt. A Retry-After
+// header takes precedence over the exponential backoff, both are capped at
+// MaxDelay.
+func (p RetryPolicy) Delay(err error, attempt int) time.Duration {
+	var statusErr *HttpStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if p.MaxDelay > 0 {
+			return min(statusErr.RetryAfter, p.MaxDelay)
+		}
+		return statusErr.RetryAfter
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(max(attempt-1, 0)))
+	if p.MaxDelay > 0 {
+		delay = math.Min(delay, float64(p.MaxDelay))
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(delay)
+}
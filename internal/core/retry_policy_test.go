@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// clientError returns the error of a GET of the URL.
+func clientError(t *testing.T, u string) error {
+	t.Helper()
+
+	res, err := http.Get(u)
+	if err == nil {
+		res.Body.Close()
+		t.Fatalf("got no error for %s", u)
+	}
+	return err
+}
+
+func TestShouldRetry(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedUrl := "http://" + listener.Addr().String() + "/"
+	listener.Close()
+
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://example.com/", Err: err}
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    bool
+	}{
+		{name: "refused connection", err: clientError(t, closedUrl), want: true},
+		{name: "request timeout", err: urlError(context.DeadlineExceeded), want: true},
+		{name: "unexpected eof", err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "retryable status", err: newHttpStatusError("u", http.StatusServiceUnavailable, http.Header{}), want: true},
+		{name: "last attempt", err: urlError(context.DeadlineExceeded), attempt: 3, want: false},
+		{name: "status", err: newHttpStatusError("u", http.StatusNotFound, http.Header{}), want: false},
+		{name: "cancelled", err: urlError(context.Canceled), want: false},
+		{name: "unsupported scheme", err: clientError(t, "ftp://example.com/"), want: false},
+		{name: "certificate", err: urlError(x509.UnknownAuthorityError{}), want: false},
+		{name: "other", err: urlError(errors.New("no recorded response")), want: false},
+		{name: "robots", err: urlError(ErrDisallowedByRobots), want: false},
+		{name: "replayed network error", err: urlError(&replayedError{message: "refused", network: true}), want: true},
+		{name: "replayed error", err: urlError(&replayedError{message: "bad certificate"}), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt := tt.attempt
+			if attempt == 0 {
+				attempt = 1
+			}
+			if got := DefaultRetryPolicy.ShouldRetry(tt.err, attempt); got != tt.want {
+				t.Errorf("ShouldRetry(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// robotsRule is an Allow or Disallow line of a robots.txt group.
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// RobotsRules are the robots.txt rules that apply to a user agent.
+type RobotsRules struct {
+	rules []robotsRule
+}
+
+// robotsPattern converts a robots.txt path, with its * and $ wildcards, to a
+// regular expression matching from the start of the path.
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	parts := strings.Split(path, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// ParseRobots reads the robots.txt groups for the user agent. The groups
+// naming the agent's product token, e.g. player-scraper for
+// "player-scraper/1.2", are used if there are any, the * groups otherwise.
+func ParseRobots(r io.Reader, userAgent string) (*RobotsRules, error) {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	token, _, _ = strings.Cut(strings.ToLower(token), "/")
+
+	var agentRules, defaultRules []robotsRule
+	// whether the current group is for the agent or *, and whether the file
+	// has a group for the agent at all
+	forAgent, forDefault, hasAgentGroup := false, false, false
+	inRules := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share the group that follows
+			if inRules {
+				forAgent, forDefault, inRules = false, false, false
+			}
+			agent := strings.ToLower(value)
+			if agent == "*" {
+				forDefault = true
+			} else if token != "" && agent == token {
+				forAgent, hasAgentGroup = true, true
+			}
+		case "allow", "disallow":
+			inRules = true
+			// an empty Disallow allows everything
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			if forAgent {
+				agentRules = append(agentRules, rule)
+			}
+			if forDefault {
+				defaultRules = append(defaultRules, rule)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if hasAgentGroup {
+		return &RobotsRules{rules: agentRules}, nil
+	}
+	return &RobotsRules{rules: defaultRules}, nil
+}
+
+// Allowed reports whether the path may be requested. The longest matching
+// rule wins and Allow wins a tie, a path no rule matches is allowed.
+func (r *RobotsRules) Allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	allowed, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
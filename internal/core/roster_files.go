@@ -0,0 +1,150 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Helpers shared by the roster loaders for resolving, parsing and storing
+// roster, INFO and academy files.
+
+// resolveFileUrl returns the absolute URL of a file location, which is
+// either absolute already or relative to the remote URL.
+func resolveFileUrl(remoteUrl string, filePath string) (string, error) {
+	if u, err := url.Parse(filePath); err == nil && u.IsAbs() {
+		return filePath, nil
+	}
+
+	return url.JoinPath(remoteUrl, filePath)
+}
+
+func localRosterPath(dir string, roster *RosterFile) string {
+	return filepath.Join(dir, roster.Code+".txt")
+}
+
+func localInfoPath(dir string, roster *RosterFile) string {
+	return filepath.Join(dir, fmt.Sprintf("INFO_%s.txt", roster.Code))
+}
+
+// ListLocalRosters returns a roster for every roster file in the directory,
+// ready to be loaded by a roster loader in local mode.
+func ListLocalRosters(dir string) ([]*RosterFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rosters := []*RosterFile{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(name), ".txt") || strings.HasPrefix(name, "INFO_") {
+			continue
+		}
+
+		code := strings.TrimSuffix(name, filepath.Ext(name))
+		rosters = append(rosters, &RosterFile{Name: code, Code: code, FileLocation: name})
+	}
+
+	return rosters, nil
+}
+
+// addChecksum records the SHA-256 of a loaded file.
+func addChecksum(roster *RosterFile, kind FileKind, content []byte) {
+	if roster.Checksums == nil {
+		roster.Checksums = map[FileKind]string{}
+	}
+	sum := sha256.Sum256(content)
+	roster.Checksums[kind] = hex.EncodeToString(sum[:])
+}
+
+func parseRosterContent(roster *RosterFile, content []byte) error {
+	addChecksum(roster, FileRoster, content)
+
+	parser := &TextRosterParser{}
+	var err error
+	roster.Columns, roster.Players, err = parser.Parse(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	addRowErrors(roster, FileRoster, roster.FileLocation, parser.RowErrors)
+
+	squad := roster.Squad
+	if squad == "" {
+		squad = SquadSenior
+	}
+	for _, p := range roster.Players {
+		p.Squad = squad
+	}
+	return nil
+}
+
+// addRowErrors records the rows of a file that were skipped on the roster.
+func addRowErrors(roster *RosterFile, kind FileKind, location string, errs []error) {
+	for _, err := range errs {
+		roster.Errors = append(roster.Errors, newScrapeError(roster, kind, location, err))
+	}
+}
+
+func parseAcademyContent(roster *RosterFile, content []byte) error {
+	addChecksum(roster, FileAcademy, content)
+
+	parser := &TextRosterParser{}
+	_, academyPlayers, err := parser.Parse(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	addRowErrors(roster, FileAcademy, roster.AcademyFileLocation, parser.RowErrors)
+
+	for _, p := range academyPlayers {
+		p.Squad = SquadAcademy
+	}
+	roster.Players = append(roster.Players, academyPlayers...)
+	return nil
+}
+
+func parseInfoContent(roster *RosterFile, content []byte) error {
+	addChecksum(roster, FileInfo, content)
+
+	info, err := ParseInfo(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	addRowErrors(roster, FileInfo, roster.InfoFileLocation, info.RowErrors)
+
+	roster.Info = info
+	return nil
+}
+
+// saveRosterFile stores a downloaded roster in the local roster directory.
+func saveRosterFile(dir string, roster *RosterFile, content []byte) error {
+	return os.WriteFile(localRosterPath(dir, roster), content, 0644)
+}
+
+// loadLocalRoster loads the roster from the local roster directory, a
+// missing file is not an error.
+func loadLocalRoster(dir string, roster *RosterFile) error {
+	content, err := os.ReadFile(localRosterPath(dir, roster))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return parseRosterContent(roster, content)
+}
+
+// loadLocalInfo loads the INFO file from the local roster directory if it
+// exists and applies it to the roster's players.
+func loadLocalInfo(dir string, roster *RosterFile) {
+	if content, err := os.ReadFile(localInfoPath(dir, roster)); err == nil {
+		parseInfoContent(roster, content)
+	}
+	roster.applyInfo()
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/fatih/color"
+)
+
+type FileKind string
+
+const (
+	FileRoster  FileKind = "roster"
+	FileInfo    FileKind = "info"
+	FileAcademy FileKind = "academy"
+)
+
+var fileKinds = []FileKind{FileRoster, FileInfo, FileAcademy}
+
+// ScrapeError is an error loading one of a club's files.
+type ScrapeError struct {
+	Code       string
+	League     string
+	Kind       FileKind
+	Url        string
+	StatusCode int
+	Attempts   int
+	Err        error
+}
+
+func newScrapeError(roster *RosterFile, kind FileKind, location string, err error) *ScrapeError {
+	scrapeErr := &ScrapeError{
+		Code:     roster.Code,
+		League:   roster.League,
+		Kind:     kind,
+		Url:      location,
+		Attempts: roster.Attempts,
+		Err:      err,
+	}
+
+	var statusErr *HttpStatusError
+	if errors.As(err, &statusErr) {
+		scrapeErr.Url = statusErr.Url
+		scrapeErr.StatusCode = statusErr.StatusCode
+	}
+
+	return scrapeErr
+}
+
+func (e *ScrapeError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Code, e.Kind, e.Err)
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// missingOptional reports whether the error is an academy or INFO file the
+// server doesn't have. Many clubs have none, so it is not a failure.
+func (e *ScrapeError) missingOptional() bool {
+	return e.Kind != FileRoster && e.StatusCode == http.StatusNotFound
+}
+
+func (e *ScrapeError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code       string   `json:"code"`
+		League     string   `json:"league"`
+		Kind       FileKind `json:"kind"`
+		Url        string   `json:"url"`
+		StatusCode int      `json:"status,omitempty"`
+		Attempts   int      `json:"attempts"`
+		Error      string   `json:"error"`
+	}{e.Code, e.League, e.Kind, e.Url, e.StatusCode, e.Attempts, e.Err.Error()})
+}
+
+// CollectErrors returns the load errors together with the errors of the
+// optional files of each roster.
+func CollectErrors(rosters []*RosterFile, loadErrors []error) []error {
+	errs := slices.Clone(loadErrors)
+	for _, r := range rosters {
+		for _, e := range r.Errors {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// PrintErrorSummary prints the errors grouped by file kind and club. Missing
+// optional files are listed separately as warnings.
+func PrintErrorSummary(errs []error) {
+	other := []error{}
+	missing := []*ScrapeError{}
+	byKind := map[FileKind][]*ScrapeError{}
+	for _, e := range errs {
+		var scrapeErr *ScrapeError
+		if errors.As(e, &scrapeErr) && scrapeErr.missingOptional() {
+			missing = append(missing, scrapeErr)
+		} else if errors.As(e, &scrapeErr) {
+			byKind[scrapeErr.Kind] = append(byKind[scrapeErr.Kind], scrapeErr)
+		} else {
+			other = append(other, e)
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.SortStableFunc(missing, compareScrapeErrors)
+		color.Yellow("Missing optional files (%d):\n", len(missing))
+		for _, e := range missing {
+			color.Yellow("  - %s: %s file\n", e.Code, e.Kind)
+		}
+	}
+
+	if len(errs) == len(missing) {
+		return
+	}
+
+	color.Red("Errors occurred while loading rosters:\n")
+
+	for _, kind := range fileKinds {
+		kindErrs := byKind[kind]
+		if len(kindErrs) == 0 {
+			continue
+		}
+
+		slices.SortStableFunc(kindErrs, compareScrapeErrors)
+
+		color.Red(" %s files (%d):\n", kind, len(kindErrs))
+		for _, e := range kindErrs {
+			color.Red("  - %s: %v\n", e.Code, e.Err)
+		}
+	}
+
+	if len(other) > 0 {
+		color.Red(" other (%d):\n", len(other))
+		for _, e := range other {
+			color.Red("  - %v\n", e)
+		}
+	}
+}
+
+func compareScrapeErrors(a, b *ScrapeError) int {
+	if a.Code < b.Code {
+		return -1
+	} else if a.Code > b.Code {
+		return 1
+	}
+	return 0
+}
+
+// errorEntries converts the errors into values that marshal to JSON objects.
+func errorEntries(errs []error) []interface{} {
+	entries := []interface{}{}
+	for _, e := range errs {
+		var scrapeErr *ScrapeError
+		if errors.As(e, &scrapeErr) {
+			entries = append(entries, scrapeErr)
+		} else {
+			entries = append(entries, map[string]string{"error": e.Error()})
+		}
+	}
+	return entries
+}
+
+// WriteErrorsFile writes the errors to a JSON file.
+func WriteErrorsFile(path string, errs []error) error {
+	content, err := json.MarshalIndent(errorEntries(errs), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestScrapeErrorMissingOptional(t *testing.T) {
+	roster := &RosterFile{Code: "ARS"}
+	notFound := newHttpStatusError("http://example.com/ARSaca.txt", http.StatusNotFound, http.Header{})
+	unavailable := newHttpStatusError("http://example.com/ARSaca.txt", http.StatusServiceUnavailable, http.Header{})
+
+	tests := []struct {
+		name string
+		err  *ScrapeError
+		want bool
+	}{
+		{name: "academy not found", err: newScrapeError(roster, FileAcademy, "", notFound), want: true},
+		{name: "info not found", err: newScrapeError(roster, FileInfo, "", notFound), want: true},
+		{name: "roster not found", err: newScrapeError(roster, FileRoster, "", notFound)},
+		{name: "academy unavailable", err: newScrapeError(roster, FileAcademy, "", unavailable)},
+		{name: "academy unreadable", err: newScrapeError(roster, FileAcademy, "", errors.New("no rows"))},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.missingOptional(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
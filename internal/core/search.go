@@ -0,0 +1,262 @@
+package core
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// Get returns the value of a player or club column. Besides the roster
+// columns it knows Team, Code, League, Side, Squad, Wage, Mkt Value, Contract, the
+// other INFO columns and the computed columns of the record.
+func (r PlayerRecord) Get(col string) (string, bool) {
+	for name, val := range r.Computed {
+		if strings.EqualFold(name, col) {
+			return strconv.FormatFloat(val, 'f', -1, 64), true
+		}
+	}
+
+	switch strings.ToLower(col) {
+	case "team":
+		return r.Team, true
+	case "code":
+		return r.Code, true
+	case "league":
+		return r.League, true
+	case "side":
+		return r.Side(), true
+	case "squad":
+		return string(r.Squad), true
+	case "wage":
+		return strconv.FormatFloat(r.Wage, 'f', -1, 64), true
+	case "value", "mkt value":
+		return strconv.FormatFloat(r.Player.Value, 'f', -1, 64), true
+	case "contract":
+		return strconv.Itoa(r.Contract), true
+	}
+
+	for name, val := range r.Info {
+		if strings.EqualFold(name, col) {
+			return val, true
+		}
+	}
+
+	return r.Player.Get(normalizeColumnName(col))
+}
+
+var conditionOps = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// Condition compares a column against a value, e.g. Sh>=14 or Nat=eng,sco.
+// Numbers are compared numerically and text case-insensitively. = and !=
+// accept a comma separated list of values and ~ matches a substring.
+type Condition struct {
+	Column string
+	Op     string
+	Value  string
+}
+
+func ParseCondition(expr string) (Condition, error) {
+	i := strings.IndexAny(expr, "!=<>~")
+	if i <= 0 {
+		return Condition{}, fmt.Errorf("invalid condition %q, expected e.g. Sh>=14", expr)
+	}
+
+	for _, op := range conditionOps {
+		if strings.HasPrefix(expr[i:], op) {
+			return Condition{
+				Column: strings.TrimSpace(expr[:i]),
+				Op:     op,
+				Value:  strings.TrimSpace(expr[i+len(op):]),
+			}, nil
+		}
+	}
+
+	return Condition{}, fmt.Errorf("invalid condition %q, expected e.g. Sh>=14", expr)
+}
+
+func (c Condition) String() string {
+	return c.Column + c.Op + c.Value
+}
+
+// Match reports whether the player satisfies the condition. A column the
+// player doesn't have never matches.
+func (c Condition) Match(r PlayerRecord) bool {
+	val, ok := r.Get(c.Column)
+	if !ok {
+		return false
+	}
+
+	switch c.Op {
+	case "=", "!=":
+		found := slices.ContainsFunc(strings.Split(c.Value, ","), func(v string) bool {
+			return compareValues(val, strings.TrimSpace(v)) == 0
+		})
+		return found == (c.Op == "=")
+	case "~":
+		return strings.Contains(strings.ToLower(val), strings.ToLower(c.Value))
+	case ">":
+		return compareValues(val, c.Value) > 0
+	case ">=":
+		return compareValues(val, c.Value) >= 0
+	case "<":
+		return compareValues(val, c.Value) < 0
+	case "<=":
+		return compareValues(val, c.Value) <= 0
+	}
+	return false
+}
+
+// compareValues compares two values as numbers if both are numeric,
+// otherwise as case-insensitive text.
+func compareValues(a string, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// SortKey orders players by a column, descending when Desc is set.
+type SortKey struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+// ParseSortKeys parses a comma separated list of columns, a column prefixed
+// with - is sorted in descending order, e.g. -Sh,Age.
+func ParseSortKeys(value string) []SortKey {
+	keys := []SortKey{}
+	for _, col := range strings.Split(value, ",") {
+		col = strings.TrimSpace(col)
+		if col == "" {
+			continue
+		}
+
+		key := SortKey{Column: strings.TrimLeft(col, "+-")}
+		key.Desc = strings.HasPrefix(col, "-")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+type PlayerQuery struct {
+	Conditions []Condition
+	Sort       []SortKey
+	Limit      int
+	// Computed are calculated for every player besides the <stat>/min columns
+	Computed []*ComputedColumn
+}
+
+// Columns returns the columns the query filters or sorts on.
+func (q PlayerQuery) Columns() []string {
+	cols := []string{}
+	for _, c := range q.Conditions {
+		cols = append(cols, c.Column)
+	}
+	for _, s := range q.Sort {
+		cols = append(cols, s.Column)
+	}
+	return cols
+}
+
+// SearchPlayers returns the players matching every condition of the query,
+// sorted and limited as requested. It fails when no player has a column the
+// query filters or sorts on, e.g. a computed column whose definition wasn't
+// given.
+func SearchPlayers(rosters []*RosterFile, q PlayerQuery) ([]PlayerRecord, error) {
+	records := computedPlayerRecords(rosters, computedColumns(q.Computed))
+	for _, col := range q.Columns() {
+		if len(records) > 0 && !slices.ContainsFunc(records, func(r PlayerRecord) bool { _, ok := r.Get(col); return ok }) {
+			return nil, fmt.Errorf("no player has a %s column", col)
+		}
+	}
+
+	matches := []PlayerRecord{}
+	for _, r := range records {
+		if !slices.ContainsFunc(q.Conditions, func(c Condition) bool { return !c.Match(r) }) {
+			matches = append(matches, r)
+		}
+	}
+
+	sortPlayerRecords(matches, q.Sort)
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, nil
+}
+
+// sortPlayerRecords sorts the records by the keys in order, keeping the
+// roster order of players that compare equal.
+func sortPlayerRecords(records []PlayerRecord, keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(records, func(a, b PlayerRecord) int {
+		for _, key := range keys {
+			x, _ := a.Get(key.Column)
+			y, _ := b.Get(key.Column)
+			if c := compareValues(x, y); c != 0 {
+				if key.Desc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// WritePlayers writes the players to w as a table, CSV or JSON.
+func WritePlayers(w io.Writer, players []PlayerRecord, cols []string, format string) error {
+	values := func(r PlayerRecord) []string {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i], _ = r.Get(col)
+		}
+		return row
+	}
+
+	switch strings.ToLower(format) {
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write(cols)
+		for _, r := range players {
+			writer.Write(values(r))
+		}
+		writer.Flush()
+		return writer.Error()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(players)
+	case "table":
+		t := table.NewWriter()
+		t.SetOutputMirror(w)
+		header := table.Row{}
+		for _, col := range cols {
+			header = append(header, col)
+		}
+		t.AppendHeader(header)
+		for _, r := range players {
+			row := table.Row{}
+			for _, v := range values(r) {
+				row = append(row, v)
+			}
+			t.AppendRow(row)
+		}
+		t.SetStyle(table.StyleLight)
+		t.Render()
+		return nil
+	default:
+		return fmt.Errorf("unsupported search format: %s", format)
+	}
+}
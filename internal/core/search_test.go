@@ -0,0 +1,55 @@
+package core
+
+import "testing"
+
+func TestSearchComputedColumnsOfCsvExport(t *testing.T) {
+	rosters := []*RosterFile{{Code: "ARS", Columns: []string{"Name", "Age", "Gam", "Min", "Gls"}, Players: []*Player{
+		{Name: "A_One", Age: 20, Gam: 10, Min: 900, Gls: 9, Squad: SquadSenior},
+		{Name: "B_Two", Age: 21, Gam: 10, Min: 900, Gls: 3, Squad: SquadSenior},
+	}}}
+	perGame, err := ParseComputedColumn("GlsPerGame = Gls / Gam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the spreadsheet formulas of the export are calculated again
+	path, err := Export(rosters, FormatCsv, ExportOptions{OutputDir: t.TempDir(), UseExcelFormulas: true, ComputedColumns: []*ComputedColumn{perGame}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		where    string
+		computed []*ComputedColumn
+		want     []string
+	}{
+		{where: "Gls/min>0.005", want: []string{"A_One"}},
+		{where: "GlsPerGame<0.5", computed: []*ComputedColumn{perGame}, want: []string{"B_Two"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCondition(tt.where)
+		if err != nil {
+			t.Fatal(err)
+		}
+		players, err := SearchPlayers(snapshot, PlayerQuery{Conditions: []Condition{c}, Computed: tt.computed})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.where, err)
+		}
+		names := []string{}
+		for _, p := range players {
+			names = append(names, p.Name)
+		}
+		if len(names) != len(tt.want) || names[0] != tt.want[0] {
+			t.Errorf("%s: got %v, want %v", tt.where, names, tt.want)
+		}
+	}
+
+	// without its definition the formula column can't be searched
+	if _, err := SearchPlayers(snapshot, PlayerQuery{Conditions: []Condition{{Column: "GlsPerGame", Op: "<", Value: "0.5"}}}); err == nil {
+		t.Error("searched a computed column without its definition, want an error")
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LoadSnapshot loads the players of a previous scrape from either an export
+// file (csv, json or ndjson) or a directory of roster files.
+func LoadSnapshot(path string) ([]*RosterFile, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		return loadRosterDir(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCsvExport(file)
+	case ".json":
+		return readJsonExport(file)
+	case ".ndjson":
+		return readNdjsonExport(file)
+	default:
+		return nil, fmt.Errorf("unsupported snapshot file: %s", path)
+	}
+}
+
+func loadRosterDir(dir string) ([]*RosterFile, error) {
+	rosters, err := ListLocalRosters(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, roster := range rosters {
+		if err := loadLocalRoster(dir, roster); err != nil {
+			return nil, fmt.Errorf("%s: %w", roster.FileLocation, err)
+		}
+		loadLocalInfo(dir, roster)
+	}
+
+	return rosters, nil
+}
+
+// rosterIndex groups players read back from an export by club code.
+type rosterIndex struct {
+	rosters []*RosterFile
+	byCode  map[string]*RosterFile
+}
+
+func (idx *rosterIndex) add(team, code, league string, p *Player) *RosterFile {
+	if idx.byCode == nil {
+		idx.byCode = map[string]*RosterFile{}
+	}
+
+	roster, ok := idx.byCode[code]
+	if !ok {
+		roster = &RosterFile{Name: team, Code: code, League: league, Players: []*Player{}}
+		idx.byCode[code] = roster
+		idx.rosters = append(idx.rosters, roster)
+	}
+	roster.Players = append(roster.Players, p)
+	return roster
+}
+
+func readCsvExport(r io.Reader) ([]*RosterFile, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	// skip the title line
+	if _, err := reader.Read(); err != nil {
+		return nil, err
+	}
+
+	var headers []string
+	idx := &rosterIndex{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if headers == nil {
+			if len(record) > 1 {
+				headers = record
+			}
+			continue
+		}
+
+		p := &Player{}
+		var team, code, league string
+		hasInfo, hasContract := false, false
+		for i, h := range headers {
+			if i >= len(record) || record[i] == "" {
+				continue
+			}
+
+			val := record[i]
+			switch {
+			case h == "Team":
+				team = val
+			case h == "Code":
+				code = val
+			case h == "League":
+				league = val
+			case h == "Squad":
+				p.Squad = Squad(val)
+			case h == "Wage":
+				p.Wage, _ = strconv.ParseFloat(val, 64)
+				hasInfo = true
+			case h == "Mkt Value":
+				p.Value, _ = strconv.ParseFloat(val, 64)
+				hasInfo = true
+			case h == "Contract":
+				p.Contract, _ = strconv.Atoi(val)
+				hasInfo, hasContract = true, true
+			case strings.HasSuffix(h, "/min"), strings.HasPrefix(val, "="):
+				// computed columns and spreadsheet formulas are calculated
+				// again from the other columns when needed
+			default:
+				if err := p.Set(h, val); err != nil {
+					return nil, fmt.Errorf("invalid %s value %q for %s", h, val, p.Name)
+				}
+			}
+		}
+
+		if p.Squad == "" {
+			p.Squad = SquadSenior
+		}
+		if hasInfo && p.Info == nil {
+			p.Info = map[string]string{}
+		}
+		roster := idx.add(team, code, league, p)
+		if hasInfo && roster.Info == nil {
+			roster.Info = &InfoFile{WageColumn: "Wage", ValueColumn: "Mkt Value"}
+		}
+		if hasContract {
+			roster.Info.ContractColumn = "Contract"
+		}
+		for _, h := range headers {
+			if _, ok := p.Get(h); ok && !slices.Contains(roster.Columns, h) {
+				roster.Columns = append(roster.Columns, h)
+			}
+		}
+	}
+
+	return idx.rosters, nil
+}
+
+func addPlayerRecord(idx *rosterIndex, rec PlayerRecord) {
+	if rec.Squad == "" {
+		rec.Squad = SquadSenior
+	}
+	hasInfo := rec.Wage != 0 || rec.Value != 0 || rec.Contract != 0
+	if hasInfo && rec.Info == nil {
+		rec.Info = map[string]string{}
+	}
+	roster := idx.add(rec.Team, rec.Code, rec.League, rec.Player)
+	if hasInfo && roster.Info == nil {
+		roster.Info = &InfoFile{WageColumn: "Wage", ValueColumn: "Mkt Value"}
+	}
+	if rec.Contract != 0 {
+		roster.Info.ContractColumn = "Contract"
+	}
+}
+
+func readJsonExport(r io.Reader) ([]*RosterFile, error) {
+	export := jsonExport{}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	idx := &rosterIndex{}
+	for _, rec := range export.Players {
+		addPlayerRecord(idx, rec)
+	}
+	return idx.rosters, nil
+}
+
+func readNdjsonExport(r io.Reader) ([]*RosterFile, error) {
+	idx := &rosterIndex{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		rec := PlayerRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, err
+		}
+		addPlayerRecord(idx, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return idx.rosters, nil
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS scrape_runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	game       TEXT NOT NULL,
+	source_url TEXT NOT NULL,
+	version    TEXT NOT NULL,
+	scraped_at TEXT NOT NULL,
+	errors     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS clubs (
+	game   TEXT NOT NULL,
+	code   TEXT NOT NULL,
+	name   TEXT NOT NULL,
+	league TEXT NOT NULL,
+	PRIMARY KEY (game, code)
+);
+CREATE TABLE IF NOT EXISTS players (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	game      TEXT NOT NULL,
+	name      TEXT NOT NULL,
+	club_code TEXT NOT NULL,
+	seq       INTEGER NOT NULL,
+	nat       TEXT NOT NULL,
+	UNIQUE (game, name, club_code, seq)
+);
+CREATE TABLE IF NOT EXISTS player_stats (
+	run_id    INTEGER NOT NULL REFERENCES scrape_runs(id),
+	player_id INTEGER NOT NULL REFERENCES players(id),
+	club_code TEXT NOT NULL,
+	league    TEXT NOT NULL,
+	squad     TEXT NOT NULL,
+	%s,
+	wage      REAL,
+	value     REAL,
+	extra     TEXT,
+	PRIMARY KEY (run_id, player_id)
+);
+CREATE INDEX IF NOT EXISTS player_stats_club ON player_stats (club_code);
+`
+
+// sqliteStatCols are the Player columns stored in each player_stats snapshot.
+var sqliteStatCols = []string{
+	"Age", "Prs", "St", "Tk", "Ps", "Sh", "Ag", "KAb", "TAb", "PAb", "SAb",
+	"Gam", "Sub", "Min", "Mom", "Sav", "Con", "Ktk", "Kps", "Sht", "Gls", "Ass",
+	"DP", "Inj", "Sus", "Fit",
+}
+
+func sqliteColumnDefs() string {
+	defs := make([]string, len(sqliteStatCols))
+	for i, col := range sqliteStatCols {
+		colType := "INTEGER"
+		if _, ok := (&Player{}).stringFields()[col]; ok {
+			colType = "TEXT"
+		}
+		defs[i] = fmt.Sprintf("%s %s", strings.ToLower(col), colType)
+	}
+	return strings.Join(defs, ",\n\t")
+}
+
+// ExportToSqlite appends the scrape to a SQLite database as a new run,
+// creating the database and its tables if they don't exist yet.
+func ExportToSqlite(rosters []*RosterFile, dbPath string, meta ScrapeMeta) (string, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(fmt.Sprintf(sqliteSchema, sqliteColumnDefs())); err != nil {
+		return "", fmt.Errorf("failed to create database schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if meta.Timestamp.IsZero() {
+		meta.Timestamp = time.Now()
+	}
+	if meta.Errors == nil {
+		meta.Errors = []string{}
+	}
+	errors, _ := json.Marshal(meta.Errors)
+	res, err := tx.Exec(
+		"INSERT INTO scrape_runs (game, source_url, version, scraped_at, errors) VALUES (?, ?, ?, ?, ?)",
+		meta.Game, meta.SourceUrl, meta.Version, meta.Timestamp.UTC().Format(time.RFC3339), string(errors),
+	)
+	if err != nil {
+		return "", err
+	}
+	runId, err := res.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+
+	statCols := make([]string, len(sqliteStatCols))
+	for i, col := range sqliteStatCols {
+		statCols[i] = strings.ToLower(col)
+	}
+	insertStats, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO player_stats (run_id, player_id, club_code, league, squad, %s, wage, value, extra) VALUES (?, ?, ?, ?, ?, %s?, ?, ?)",
+		strings.Join(statCols, ", "),
+		strings.Repeat("?, ", len(statCols)),
+	))
+	if err != nil {
+		return "", err
+	}
+	defer insertStats.Close()
+
+	playerCount := 0
+	for _, r := range rosters {
+		if r.Players == nil {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			"INSERT INTO clubs (game, code, name, league) VALUES (?, ?, ?, ?) ON CONFLICT (game, code) DO UPDATE SET name = excluded.name, league = excluded.league",
+			meta.Game, r.Code, r.Name, r.League,
+		); err != nil {
+			return "", err
+		}
+
+		// namesakes at a club are numbered in roster order, like in a diff
+		seqs := map[string]int{}
+		for _, p := range r.Players {
+			seqs[p.Name]++
+			var playerId int64
+			err := tx.QueryRow(
+				"INSERT INTO players (game, name, club_code, seq, nat) VALUES (?, ?, ?, ?, ?) ON CONFLICT (game, name, club_code, seq) DO UPDATE SET nat = excluded.nat RETURNING id",
+				meta.Game, p.Name, r.Code, seqs[p.Name], p.Nat,
+			).Scan(&playerId)
+			if err != nil {
+				return "", err
+			}
+
+			args := []interface{}{runId, playerId, r.Code, r.League, string(p.Squad)}
+			for _, col := range sqliteStatCols {
+				if f, ok := p.stringFields()[col]; ok {
+					args = append(args, *f)
+				} else {
+					args = append(args, p.Int(col))
+				}
+			}
+
+			var wage, value, extra interface{}
+			if r.Info != nil && p.hasInfo() {
+				wage, value = p.Wage, p.Value
+			}
+			if len(p.Extra) > 0 {
+				b, _ := json.Marshal(p.Extra)
+				extra = string(b)
+			}
+			args = append(args, wage, value, extra)
+
+			if _, err := insertStats.Exec(args...); err != nil {
+				return "", fmt.Errorf("failed to store %s of %s: %w", p.Name, r.Code, err)
+			}
+			playerCount++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	absPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		absPath = dbPath
+	}
+	color.Green("Database\t\t ... %s (run %d, %d players).", absPath, runId, playerCount)
+	return absPath, nil
+}
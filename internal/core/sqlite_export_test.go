@@ -0,0 +1,69 @@
+package core
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func countRows(t *testing.T, dbPath string, query string) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestExportToSqliteSameNames(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "players.db")
+	rosters := []*RosterFile{
+		{Code: "ARS", League: "premier", Players: []*Player{
+			{Name: "J_Smith", Nat: "eng", Squad: SquadSenior},
+			{Name: "J_Smith", Nat: "eng", Squad: SquadAcademy},
+		}},
+		{Code: "CHE", League: "premier", Players: []*Player{
+			{Name: "J_Smith", Nat: "sco", Squad: SquadSenior},
+		}},
+	}
+
+	if _, err := ExportToSqlite(rosters, dbPath, ScrapeMeta{Game: "ffo"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM player_stats"); n != 3 {
+		t.Errorf("got %d snapshots, want 3", n)
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM players"); n != 3 {
+		t.Errorf("got %d players, want 3", n)
+	}
+}
+
+func TestExportToSqliteNamesakesAtOneClub(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "players.db")
+	rosters := []*RosterFile{
+		{Code: "ARS", League: "premier", Players: []*Player{
+			{Name: "J_Smith", Nat: "eng", Age: 25, Squad: SquadSenior},
+			{Name: "J_Smith", Nat: "eng", Age: 30, Squad: SquadSenior},
+		}},
+	}
+
+	// the second run finds the same players by their number
+	for i := 0; i < 2; i++ {
+		if _, err := ExportToSqlite(rosters, dbPath, ScrapeMeta{Game: "ffo"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM players"); n != 2 {
+		t.Errorf("got %d players, want 2", n)
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM player_stats s JOIN players p ON p.id = s.player_id WHERE p.seq = 2 AND s.age = 30"); n != 2 {
+		t.Errorf("got %d snapshots of the second J_Smith, want 2", n)
+	}
+}
@@ -1,27 +1,116 @@
 package core
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type ScraperOptions struct {
-	LocalOnly     bool
-	DownloadFiles bool
-	RosterDir     string
-	OutputDir     string
-	ExcelExport   bool
+	LocalOnly      bool         `json:"localOnly"`
+	DownloadFiles  bool         `json:"downloadFiles"`
+	RosterDir      string       `json:"rosterDir"`
+	OutputDir      string       `json:"outputDir"`
+	ExcelExport    bool         `json:"excelExport"`
+	Format         ExportFormat `json:"format"`
+	Columns        []string     `json:"columns,omitempty"`
+	Sort           []SortKey    `json:"sort,omitempty"`
+	ExcludeAcademy bool         `json:"excludeAcademy"`
+	Leagues        []string     `json:"leagues,omitempty"`
+	Squads         []Squad      `json:"squads,omitempty"`
 }
 
+type TeamProvider interface {
+	Load() ([]*RosterFile, error)
+}
+
+// RosterLoader loads the players of the rosters. Load returns once every
+// roster has been loaded, has failed or was skipped because the context was
+// cancelled, and all the callbacks have run.
 type RosterLoader interface {
-	Load(rosters []*RosterFile, context context.Context)
+	Load(rosters []*RosterFile, context context.Context) *LoadResult
+}
+
+// LoadResult is the outcome of RosterLoader.Load.
+type LoadResult struct {
+	Loaded []*RosterFile
+	Errors []error
+	// Skipped are the rosters that were not loaded because the context was
+	// cancelled
+	Skipped []*RosterFile
+	// Timings is the time spent on each roster by code, retries included
+	Timings   map[string]time.Duration
+	Duration  time.Duration
+	Cancelled bool
+}
+
+// rosterOutcome is what a loader reports for a roster, err is nil when the
+// roster loaded and the roster is skipped when the load was cancelled.
+type rosterOutcome struct {
+	roster  *RosterFile
+	err     error
+	skipped bool
+}
+
+func newLoadResult() *LoadResult {
+	return &LoadResult{Timings: map[string]time.Duration{}}
+}
+
+func (r *LoadResult) add(o rosterOutcome) {
+	switch {
+	case o.skipped:
+		r.Skipped = append(r.Skipped, o.roster)
+		return
+	case o.err != nil:
+		r.Errors = append(r.Errors, o.err)
+	default:
+		r.Loaded = append(r.Loaded, o.roster)
+	}
+	r.Timings[o.roster.Code] = o.roster.Duration
 }
 
 type RosterFile struct {
-	Name                string
-	Code                string
-	League              string
+	Name   string
+	Code   string
+	League string
+	// Squad is the squad of the players of the roster file, Senior unless
+	// the club is a youth team
+	Squad               Squad
 	FileLocation        string
 	InfoFileLocation    string
 	AcademyFileLocation string
-	Rows                *[][]string
-	InfoRows            *[][]string
-	Failures            int
+	Columns             []string
+	Players             []*Player
+	Info                *InfoFile
+	Attempts            int
+	Errors              []*ScrapeError
+	Checksums           map[FileKind]string
+	Duration            time.Duration
+}
+
+// applyInfo copies the contract data of the roster's INFO file onto the
+// matching players. The market value is kept in millions as exported, a
+// value written without a K, M or B suffix is taken to be in millions
+// already.
+func (r *RosterFile) applyInfo() {
+	if r.Info == nil {
+		return
+	}
+
+	for _, p := range r.Players {
+		info := r.Info.Player(p.Name)
+		if info == nil {
+			continue
+		}
+
+		p.Wage = info.Wage
+		p.Value = info.Value
+		if info.ValueUnit > 1 {
+			p.Value /= 1e6
+		}
+		p.Contract = info.Contract
+		p.Info = map[string]string{}
+		for _, col := range r.Info.ExtraColumns() {
+			p.Info[col] = info.Values[col]
+		}
+	}
 }
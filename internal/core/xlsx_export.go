@@ -0,0 +1,236 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/xuri/excelize/v2"
+)
+
+const allPlayersSheet = "All players"
+
+var (
+	sheetNameRegex = regexp.MustCompile(`[\[\]:*?/\\]`)
+	// columns that are never written as numbers, even if they look numeric
+	textCols = []string{"Team", "Code", "League", "Squad", "Name", "Nat", "Prs"}
+)
+
+func sheetName(name string, existing []string) string {
+	name = strings.TrimSpace(sheetNameRegex.ReplaceAllString(name, " "))
+	if name == "" {
+		name = "Unknown"
+	}
+	name = truncateRunes(name, 31)
+
+	unique := name
+	for i := 2; slices.Contains(existing, unique); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		unique = truncateRunes(name, 31-len(suffix)) + suffix
+	}
+	return unique
+}
+
+// truncateRunes shortens the string to at most n runes, sheet names are
+// limited in characters and cutting bytes could split one.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+type xlsxStyles struct {
+	header int
+	wage   int
+	value  int
+	ratio  int
+}
+
+func createXlsxStyles(f *excelize.File) (xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+
+	if styles.header, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return styles, err
+	}
+	// the clubs' INFO files may use different currencies
+	wageFmt := "#,##0"
+	if styles.wage, err = f.NewStyle(&excelize.Style{CustomNumFmt: &wageFmt}); err != nil {
+		return styles, err
+	}
+	valueFmt := `#,##0.0#"M"`
+	if styles.value, err = f.NewStyle(&excelize.Style{CustomNumFmt: &valueFmt}); err != nil {
+		return styles, err
+	}
+	ratioFmt := "0.0000"
+	if styles.ratio, err = f.NewStyle(&excelize.Style{CustomNumFmt: &ratioFmt}); err != nil {
+		return styles, err
+	}
+
+	return styles, nil
+}
+
+// writeXlsxSheet writes the rows to the sheet. Computed columns hold their
+// value and, when useFormulas is set, the formula that calculates it.
+func writeXlsxSheet(f *excelize.File, sheet string, headers []string, records [][]string, computed map[string]*ComputedColumn, useFormulas bool, styles xlsxStyles) error {
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	if err := f.SetSheetRow(sheet, "A1", &headers); err != nil {
+		return err
+	}
+	lastHeader, _ := excelize.CoordinatesToCellName(len(headers), 1)
+	if err := f.SetCellStyle(sheet, "A1", lastHeader, styles.header); err != nil {
+		return err
+	}
+
+	cellRef := func(rowNum int) func(col string) string {
+		return func(col string) string {
+			cell, _ := excelize.CoordinatesToCellName(slices.Index(headers, exportColumnName(col))+1, rowNum)
+			return cell
+		}
+	}
+
+	for i, record := range records {
+		rowNum := i + 2
+		for j, val := range record {
+			h := headers[j]
+			cell, _ := excelize.CoordinatesToCellName(j+1, rowNum)
+			var err error
+			switch {
+			case val == "":
+				continue
+			case computed[h] != nil:
+				num, _ := strconv.ParseFloat(val, 64)
+				err = f.SetCellFloat(sheet, cell, num, -1, 64)
+				if useFormulas && err == nil && len(computed[h].missingColumns(headers)) == 0 {
+					err = f.SetCellFormula(sheet, cell, fmt.Sprintf("IFERROR(%s,0)", computed[h].Expr.Formula(cellRef(rowNum))))
+				}
+				if err == nil {
+					err = f.SetCellStyle(sheet, cell, cell, styles.ratio)
+				}
+			case slices.Contains(textCols, h):
+				err = f.SetCellStr(sheet, cell, val)
+			default:
+				num, parseErr := strconv.ParseFloat(val, 64)
+				if parseErr != nil {
+					err = f.SetCellStr(sheet, cell, val)
+					break
+				}
+				if err = f.SetCellFloat(sheet, cell, num, -1, 64); err == nil {
+					if h == "Wage" {
+						err = f.SetCellStyle(sheet, cell, cell, styles.wage)
+					} else if h == "Mkt Value" {
+						err = f.SetCellStyle(sheet, cell, cell, styles.value)
+					}
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	lastCell, _ := excelize.CoordinatesToCellName(len(headers), max(len(records)+1, 2))
+	return f.AutoFilter(sheet, "A1:"+lastCell, nil)
+}
+
+// ExportToXlsx writes an Excel workbook with every player on the first
+// sheet, followed by a sheet for the academy and youth players and one
+// sheet per league.
+func ExportToXlsx(rosters []*RosterFile, opts ExportOptions) (string, error) {
+	file, err := createExportFile(opts.OutputDir, opts.FileNamePrefix, opts.Meta.Timestamp, "xlsx")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	warnHeaderMismatches(rosters)
+	tableOpts := opts
+	tableOpts.UseExcelFormulas = false
+	headers, records, computed := buildPlayerTable(rosters, tableOpts)
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := createXlsxStyles(f)
+	if err != nil {
+		return "", err
+	}
+
+	if err := f.SetSheetName(f.GetSheetName(0), allPlayersSheet); err != nil {
+		return "", err
+	}
+	if err := writeXlsxSheet(f, allPlayersSheet, headers, records, computed, opts.UseExcelFormulas, styles); err != nil {
+		return "", err
+	}
+
+	// the league sheets need the League column
+	leagueCol := slices.Index(headers, "League")
+	leagues := []string{}
+	byLeague := map[string][][]string{}
+	for _, record := range records {
+		if leagueCol < 0 {
+			break
+		}
+		league := record[leagueCol]
+		if _, ok := byLeague[league]; !ok {
+			leagues = append(leagues, league)
+		}
+		byLeague[league] = append(byLeague[league], record)
+	}
+
+	sheets := []string{allPlayersSheet}
+
+	// academy and youth players also get a sheet of their own
+	squadCol := slices.Index(headers, "Squad")
+	for _, squad := range Squads[1:] {
+		squadRecords := [][]string{}
+		for _, record := range records {
+			if squadCol >= 0 && record[squadCol] == string(squad) {
+				squadRecords = append(squadRecords, record)
+			}
+		}
+		if len(squadRecords) == 0 {
+			continue
+		}
+
+		name := sheetName(string(squad), sheets)
+		sheets = append(sheets, name)
+		if _, err := f.NewSheet(name); err != nil {
+			return "", err
+		}
+		if err := writeXlsxSheet(f, name, headers, squadRecords, computed, opts.UseExcelFormulas, styles); err != nil {
+			return "", err
+		}
+	}
+
+	for _, league := range leagues {
+		name := sheetName(league, sheets)
+		sheets = append(sheets, name)
+		if _, err := f.NewSheet(name); err != nil {
+			return "", err
+		}
+		if err := writeXlsxSheet(f, name, headers, byLeague[league], computed, opts.UseExcelFormulas, styles); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := f.WriteTo(file); err != nil {
+		return "", err
+	}
+
+	color.Blue("Finished\t\t ... Players=%s, Clubs=%d\n", formatPlayerCount(rosters), countClubs(rosters))
+	return exportPath(file), nil
+}
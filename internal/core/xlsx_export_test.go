@@ -0,0 +1,33 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSheetName(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		want     string
+	}{
+		{name: "Premier League", want: "Premier League"},
+		{name: "a/b:c", want: "a b c"},
+		{name: "  ", want: "Unknown"},
+		{name: strings.Repeat("x", 40), want: strings.Repeat("x", 31)},
+		{name: strings.Repeat("é", 40), want: strings.Repeat("é", 31)},
+		{name: "Liga", existing: []string{"Liga"}, want: "Liga (2)"},
+		{name: strings.Repeat("ü", 31), existing: []string{strings.Repeat("ü", 31)}, want: strings.Repeat("ü", 27) + " (2)"},
+	}
+
+	for _, tt := range tests {
+		got := sheetName(tt.name, tt.existing)
+		if got != tt.want {
+			t.Errorf("sheetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("sheetName(%q) = %q is not valid UTF-8", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,706 @@
+package e2e
+
+import (
+	"context"
+	"encoding/pem"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"player-scraper/internal/core"
+	_ "player-scraper/internal/ffo"
+	"player-scraper/internal/fixture"
+	"player-scraper/internal/generic"
+	_ "player-scraper/internal/ssl"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var scrapedOn = time.Date(2024, time.August, 1, 12, 0, 0, 0, time.Local)
+
+// scrape runs the provider, FileRosterLoader and CSV export of the game
+// against the server and returns the loaded clubs by code and the export.
+// The provider and loader make their requests through the transport.
+func scrape(t *testing.T, game string, srv *fixture.Server, transport http.RoundTripper, setup func(*core.FileRosterLoader)) (map[string]*core.RosterFile, []byte) {
+	t.Helper()
+
+	g, err := core.GetGame(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rosters, err := g.NewTeamProvider(srv.TeamsUrl(), core.ProviderOptions{Transport: transport}).Load()
+	if err != nil {
+		t.Fatalf("failed to load clubs: %v", err)
+	}
+
+	policy := core.DefaultRetryPolicy
+	policy.BaseDelay = 10 * time.Millisecond
+	policy.Jitter = 0
+	loader := &core.FileRosterLoader{
+		RemoteUrl:     srv.URL,
+		Dir:           t.TempDir(),
+		MaxConcurrent: 3,
+		Transport:     transport,
+		RetryPolicy:   &policy,
+	}
+	if setup != nil {
+		setup(loader)
+	}
+	loader.Load(rosters, context.Background())
+
+	exportFile, err := core.ExportToCsv(rosters, core.ExportOptions{
+		OutputDir:        t.TempDir(),
+		FileNamePrefix:   game + "_players_",
+		Title:            g.Title + " Player List",
+		UseExcelFormulas: true,
+		Meta:             core.ScrapeMeta{Game: game, Timestamp: scrapedOn},
+	})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	content, err := os.ReadFile(exportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clubs := map[string]*core.RosterFile{}
+	for _, r := range rosters {
+		clubs[r.Code] = r
+	}
+	return clubs, content
+}
+
+// assertGolden compares the export with testdata/<name>.csv, rewriting the
+// file instead when the tests run with -update.
+func assertGolden(t *testing.T, name string, content []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".csv")
+	if *update {
+		if err := os.WriteFile(golden, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(want) {
+		t.Errorf("export differs from %s:\n%s", golden, content)
+	}
+}
+
+func assertAttempts(t *testing.T, r *core.RosterFile, attempts int) {
+	t.Helper()
+	if r.Attempts != attempts {
+		t.Errorf("%s: got %d attempts, want %d", r.Code, r.Attempts, attempts)
+	}
+}
+
+func assertFailed(t *testing.T, r *core.RosterFile) {
+	t.Helper()
+	if r.Players != nil {
+		t.Errorf("%s: got %d players, want the club to fail", r.Code, len(r.Players))
+	}
+}
+
+func TestFfo(t *testing.T) {
+	const (
+		arsRoster = "/text_files/premier/roster/ARS.txt"
+		cheRoster = "/text_files/premier/roster/CHE.txt"
+		cheInfo   = "/text_files/premier/wages/INFO_CHE.txt"
+	)
+
+	tests := []struct {
+		name   string
+		golden string
+		setup  func(*fixture.Server, *core.FileRosterLoader)
+		check  func(*testing.T, map[string]*core.RosterFile, *fixture.Server)
+	}{
+		{
+			name:   "all clubs",
+			golden: "ffo",
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, _ *fixture.Server) {
+				if len(clubs) != 3 {
+					t.Fatalf("got %d clubs, want 3", len(clubs))
+				}
+				// only Arsenal has an academy file and Leeds has no INFO file
+				// either, both are optional
+				if len(clubs["CHE"].Errors) != 1 || len(clubs["LEE"].Errors) != 2 {
+					t.Errorf("got %d and %d optional file errors, want 1 and 2", len(clubs["CHE"].Errors), len(clubs["LEE"].Errors))
+				}
+				for _, c := range clubs {
+					assertAttempts(t, c, 1)
+				}
+			},
+		},
+		{
+			name:   "missing roster",
+			golden: "ffo_without_che",
+			setup: func(srv *fixture.Server, _ *core.FileRosterLoader) {
+				srv.Fail(cheRoster, http.StatusNotFound)
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, srv *fixture.Server) {
+				assertFailed(t, clubs["CHE"])
+				assertAttempts(t, clubs["CHE"], 1)
+				if n := srv.Requests(cheInfo); n != 0 {
+					t.Errorf("got %d INFO requests for a failed club, want 0", n)
+				}
+			},
+		},
+		{
+			name:   "malformed roster",
+			golden: "ffo_without_che",
+			setup: func(srv *fixture.Server, _ *core.FileRosterLoader) {
+				srv.Replace(cheRoster, "Name Age Nat Prs St\nR_Sanchez 29 esp C\n")
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, _ *fixture.Server) {
+				assertFailed(t, clubs["CHE"])
+				assertAttempts(t, clubs["CHE"], 1)
+			},
+		},
+		{
+			name:   "malformed info",
+			golden: "ffo_without_che_info",
+			setup: func(srv *fixture.Server, _ *core.FileRosterLoader) {
+				srv.Replace(cheInfo, "Name Wage Value\nR_Sanchez £40,000 £1.2.3M\n")
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, _ *fixture.Server) {
+				if len(clubs["CHE"].Players) != 5 || clubs["CHE"].Info != nil {
+					t.Errorf("got %d players and INFO %v, want 5 players without INFO", len(clubs["CHE"].Players), clubs["CHE"].Info)
+				}
+			},
+		},
+		{
+			name:   "server error retried",
+			golden: "ffo",
+			setup: func(srv *fixture.Server, _ *core.FileRosterLoader) {
+				srv.FailTimes(arsRoster, http.StatusServiceUnavailable, 1)
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, srv *fixture.Server) {
+				assertAttempts(t, clubs["ARS"], 2)
+				if n := srv.Requests(arsRoster); n != 2 {
+					t.Errorf("got %d requests, want 2", n)
+				}
+			},
+		},
+		{
+			name:   "slow roster",
+			golden: "ffo",
+			setup: func(srv *fixture.Server, _ *core.FileRosterLoader) {
+				srv.Delay(arsRoster, 300*time.Millisecond)
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, _ *fixture.Server) {
+				assertAttempts(t, clubs["ARS"], 1)
+				if d := clubs["ARS"].Duration; d < 300*time.Millisecond {
+					t.Errorf("got a duration of %v, want at least the delay", d)
+				}
+			},
+		},
+		{
+			name:   "roster timeout",
+			golden: "ffo_without_ars",
+			setup: func(srv *fixture.Server, l *core.FileRosterLoader) {
+				srv.Delay(arsRoster, 5*time.Second)
+				l.RequestTimeout = 100 * time.Millisecond
+				l.RetryPolicy.MaxAttempts = 2
+			},
+			check: func(t *testing.T, clubs map[string]*core.RosterFile, srv *fixture.Server) {
+				assertFailed(t, clubs["ARS"])
+				assertAttempts(t, clubs["ARS"], 2)
+				if n := srv.Requests(arsRoster); n != 2 {
+					t.Errorf("got %d requests, want 2", n)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fixture.NewServer(fixture.SiteFFO)
+			defer srv.Close()
+
+			clubs, content := scrape(t, "ffo", srv, nil, func(l *core.FileRosterLoader) {
+				if tt.setup != nil {
+					tt.setup(srv, l)
+				}
+			})
+			assertGolden(t, tt.golden, content)
+			if tt.check != nil {
+				tt.check(t, clubs, srv)
+			}
+		})
+	}
+}
+
+func TestSsl(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteSSL)
+	defer srv.Close()
+
+	clubs, content := scrape(t, "ssl", srv, nil, nil)
+	assertGolden(t, "ssl", content)
+
+	if len(clubs) != 4 {
+		t.Fatalf("got %d clubs, want 4", len(clubs))
+	}
+	for code, squad := range map[string]core.Squad{"ars": core.SquadSenior, "arsy": core.SquadYouth, "cely": core.SquadYouth} {
+		for _, p := range clubs[code].Players {
+			if p.Squad != squad {
+				t.Errorf("%s: got squad %s for %s, want %s", code, p.Squad, p.Name, squad)
+			}
+		}
+	}
+}
+
+func TestSslMissingYouthRoster(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteSSL)
+	defer srv.Close()
+	srv.Fail("/rosters/cely.txt", http.StatusNotFound)
+
+	clubs, content := scrape(t, "ssl", srv, nil, nil)
+	assertGolden(t, "ssl_without_cely", content)
+	assertFailed(t, clubs["cely"])
+}
+
+// ffoGamesConfig defines the FFO site as a generic game, the teams URL is
+// replaced by the fixture server's.
+const ffoGamesConfig = `games:
+  - name: ffo_generic
+    title: FFO
+    teamsUrl: http://localhost/clubs.html
+    followSelector: a[href^="clubs/"]
+    clubLinkSelector: a[href^="club_pages/"]
+    code: { from: href, pattern: '([^/]+)\.htm$' }
+    league: { from: page, pattern: '([^/]+)\.html$' }
+    clubName: { from: text }
+    rosterUrl: text_files/{league}/roster/{code}.txt
+    infoUrl: text_files/{league}/wages/INFO_{code}.txt
+    academyUrl: text_files/{league}/academy/{code}.txt
+`
+
+func TestGenericGame(t *testing.T) {
+	if _, err := core.GetGame("ffo_generic"); err != nil {
+		config := filepath.Join(t.TempDir(), "games.yaml")
+		if err := os.WriteFile(config, []byte(ffoGamesConfig), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := generic.RegisterGames(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := fixture.NewServer(fixture.SiteFFO)
+	defer srv.Close()
+
+	want, _ := scrape(t, "ffo", srv, nil, nil)
+	got, _ := scrape(t, "ffo_generic", srv, nil, nil)
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d clubs, want %d", len(got), len(want))
+	}
+	for code, w := range want {
+		g := got[code]
+		if g == nil {
+			t.Errorf("%s: club not found", code)
+			continue
+		}
+		if g.League != w.League || len(g.Players) != len(w.Players) || (g.Info == nil) != (w.Info == nil) {
+			t.Errorf("%s: got %s with %d players, want %s with %d players", code, g.League, len(g.Players), w.League, len(w.Players))
+		}
+	}
+	if got["ARS"] != nil && got["ARS"].Name != "Arsenal" {
+		t.Errorf("got club name %q, want Arsenal", got["ARS"].Name)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteFFO)
+	srv.FailTimes("/text_files/premier/roster/CHE.txt", http.StatusServiceUnavailable, 1)
+	srv.Fail("/text_files/championship/roster/LEE.txt", http.StatusNotFound)
+
+	dir := t.TempDir()
+	recorder, err := core.NewHttpRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, recorded := scrape(t, "ffo", srv, recorder, nil)
+	srv.Close()
+
+	replay, err := core.NewHttpReplay(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clubs, replayed := scrape(t, "ffo", srv, replay, nil)
+	if string(replayed) != string(recorded) {
+		t.Errorf("replayed export differs from the recorded one:\n%s", replayed)
+	}
+	// the 503 is replayed before the roster
+	assertAttempts(t, clubs["CHE"], 2)
+	assertFailed(t, clubs["LEE"])
+}
+
+func TestRecordAndReplayTimeout(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteFFO)
+	srv.Delay("/text_files/premier/roster/CHE.txt", time.Second)
+	timeout := func(l *core.FileRosterLoader) { l.RequestTimeout = 50 * time.Millisecond }
+
+	dir := t.TempDir()
+	recorder, err := core.NewHttpRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clubs, recorded := scrape(t, "ffo", srv, recorder, timeout)
+	srv.Close()
+	assertAttempts(t, clubs["CHE"], 3)
+	assertFailed(t, clubs["CHE"])
+
+	replay, err := core.NewHttpReplay(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clubs, replayed := scrape(t, "ffo", srv, replay, timeout)
+	if string(replayed) != string(recorded) {
+		t.Errorf("replayed export differs from the recorded one:\n%s", replayed)
+	}
+	// every timed out attempt is replayed as a timeout and retried
+	assertAttempts(t, clubs["CHE"], 3)
+	assertFailed(t, clubs["CHE"])
+}
+
+// loadFfo loads the FFO clubs from the server with the loader.
+func loadFfo(t *testing.T, srv *fixture.Server, ctx context.Context, loader core.RosterLoader) ([]*core.RosterFile, *core.LoadResult) {
+	t.Helper()
+
+	g, err := core.GetGame("ffo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rosters, err := g.NewTeamProvider(srv.TeamsUrl(), core.ProviderOptions{}).Load()
+	if err != nil {
+		t.Fatalf("failed to load clubs: %v", err)
+	}
+
+	return rosters, loader.Load(rosters, ctx)
+}
+
+func TestLoadResult(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteFFO)
+	defer srv.Close()
+	srv.Fail("/text_files/championship/roster/LEE.txt", http.StatusNotFound)
+
+	loaded, failed := 0, 0
+	loader := &core.FileRosterLoader{
+		RemoteUrl:     srv.URL,
+		MaxConcurrent: 2,
+		OnLoaded:      func(*core.RosterFile) { loaded++ },
+		OnError:       func(error) { failed++ },
+	}
+	_, result := loadFfo(t, srv, context.Background(), loader)
+
+	// the callbacks have all run by the time Load returns
+	if loaded != 2 || len(result.Loaded) != 2 {
+		t.Errorf("got %d callbacks and %d loaded clubs, want 2", loaded, len(result.Loaded))
+	}
+	if failed != 1 || len(result.Errors) != 1 {
+		t.Errorf("got %d callbacks and %d errors, want 1", failed, len(result.Errors))
+	}
+	if len(result.Skipped) != 0 || result.Cancelled {
+		t.Errorf("got %d skipped clubs, want none", len(result.Skipped))
+	}
+	for _, code := range []string{"ARS", "CHE", "LEE"} {
+		if _, ok := result.Timings[code]; !ok {
+			t.Errorf("got no timing for %s", code)
+		}
+	}
+}
+
+func TestLoadCancelled(t *testing.T) {
+	loaders := map[string]func(string, func(*core.RosterFile)) core.RosterLoader{
+		"http": func(url string, onLoaded func(*core.RosterFile)) core.RosterLoader {
+			return &core.FileRosterLoader{RemoteUrl: url, MaxConcurrent: 1, OnLoaded: onLoaded}
+		},
+		"colly": func(url string, onLoaded func(*core.RosterFile)) core.RosterLoader {
+			return &core.CollyRosterLoader{RemoteUrl: url, MaxConcurrent: 1, OnLoaded: onLoaded}
+		},
+	}
+
+	for name, newLoader := range loaders {
+		t.Run(name, func(t *testing.T) {
+			srv := fixture.NewServer(fixture.SiteFFO)
+			defer srv.Close()
+			srv.Delay("/text_files/premier/roster/ARS.txt", 10*time.Second)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			loaded := []string{}
+			loader := newLoader(srv.URL, func(r *core.RosterFile) { loaded = append(loaded, r.Code) })
+			start := time.Now()
+			rosters, result := loadFfo(t, srv, ctx, loader)
+
+			if d := time.Since(start); d > 2*time.Second {
+				t.Errorf("load took %v after the cancellation", d)
+			}
+			if !result.Cancelled {
+				t.Error("got a result that was not cancelled")
+			}
+			if len(loaded) != len(result.Loaded) {
+				t.Errorf("got %d callbacks for %d loaded clubs", len(loaded), len(result.Loaded))
+			}
+			if n := len(result.Loaded) + len(result.Errors) + len(result.Skipped); n != len(rosters) {
+				t.Errorf("got %d clubs in the result, want all %d", n, len(rosters))
+			}
+			skipped := map[string]bool{}
+			for _, r := range result.Skipped {
+				skipped[r.Code] = true
+				if r.Players != nil {
+					t.Errorf("%s: got %d players for a skipped club", r.Code, len(r.Players))
+				}
+			}
+			if !skipped["ARS"] {
+				t.Errorf("got ARS loaded, want it skipped")
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("got errors %v, want none for a cancelled load", result.Errors)
+			}
+		})
+	}
+}
+
+func TestCollyRequestTimeout(t *testing.T) {
+	const arsRoster = "/text_files/premier/roster/ARS.txt"
+
+	srv := fixture.NewServer(fixture.SiteFFO)
+	defer srv.Close()
+	srv.Delay(arsRoster, 5*time.Second)
+
+	policy := core.DefaultRetryPolicy
+	policy.BaseDelay = 10 * time.Millisecond
+	policy.MaxAttempts = 2
+	loader := &core.CollyRosterLoader{
+		RemoteUrl:      srv.URL,
+		MaxConcurrent:  3,
+		RequestTimeout: 100 * time.Millisecond,
+		RetryPolicy:    &policy,
+	}
+	rosters, result := loadFfo(t, srv, context.Background(), loader)
+
+	if len(result.Loaded) != 2 || len(result.Errors) != 1 {
+		t.Errorf("got %d loaded clubs and %d errors, want 2 and 1", len(result.Loaded), len(result.Errors))
+	}
+	for _, r := range rosters {
+		if r.Code == "ARS" {
+			assertFailed(t, r)
+			assertAttempts(t, r, 2)
+		}
+	}
+	if n := srv.Requests(arsRoster); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	for _, site := range []fixture.Site{fixture.SiteFFO, fixture.SiteSSL} {
+		t.Run(string(site), func(t *testing.T) {
+			srv := fixture.NewServer(site)
+			defer srv.Close()
+			srv.Delay(strings.TrimPrefix(srv.TeamsUrl(), srv.URL), 5*time.Second)
+
+			g, err := core.GetGame(string(site))
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			_, err = g.NewTeamProvider(srv.TeamsUrl(), core.ProviderOptions{RequestTimeout: 100 * time.Millisecond}).Load()
+			if err == nil {
+				t.Error("got no error for a teams page that timed out")
+			}
+			if d := time.Since(start); d > 2*time.Second {
+				t.Errorf("load took %v, want the request to time out", d)
+			}
+		})
+	}
+}
+
+// userAgents records the User-Agent of every request it passes on.
+type userAgents struct {
+	mu     sync.Mutex
+	agents map[string]bool
+}
+
+func (u *userAgents) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.mu.Lock()
+	u.agents[req.Header.Get("User-Agent")] = true
+	u.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPoliteTransport(t *testing.T) {
+	for _, loader := range []string{"http", "colly"} {
+		t.Run(loader, func(t *testing.T) {
+			srv := fixture.NewServer(fixture.SiteFFO)
+			defer srv.Close()
+
+			agents := &userAgents{agents: map[string]bool{}}
+			transport := &core.PoliteTransport{
+				UserAgent:         "player-scraper/test",
+				RequestsPerSecond: 20,
+				Burst:             1,
+				Transport:         agents,
+			}
+
+			g, err := core.GetGame("ffo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			rosters, err := g.NewTeamProvider(srv.TeamsUrl(), core.ProviderOptions{Transport: transport}).Load()
+			if err != nil {
+				t.Fatalf("failed to load clubs: %v", err)
+			}
+			var l core.RosterLoader = &core.FileRosterLoader{RemoteUrl: srv.URL, MaxConcurrent: 3, Transport: transport}
+			if loader == "colly" {
+				l = &core.CollyRosterLoader{RemoteUrl: srv.URL, MaxConcurrent: 3, Transport: transport}
+			}
+			l.Load(rosters, context.Background())
+
+			if len(agents.agents) != 1 || !agents.agents["player-scraper/test"] {
+				t.Errorf("got user agents %v, want only player-scraper/test", agents.agents)
+			}
+			// 3 clubs pages and 3 files for each of the 3 clubs, the first
+			// request doesn't wait
+			if d, want := time.Since(start), 11*50*time.Millisecond; d < want {
+				t.Errorf("got %d requests in %v, want at least %v", 12, d, want)
+			}
+		})
+	}
+}
+
+func TestRobots(t *testing.T) {
+	const cheInfo = "/text_files/premier/wages/INFO_CHE.txt"
+
+	srv := fixture.NewServer(fixture.SiteFFO)
+	defer srv.Close()
+	srv.Replace("/robots.txt", "User-agent: *\nDisallow: /text_files/premier/roster/CHE\n\nUser-agent: other\nDisallow: /\n")
+
+	transport := &core.PoliteTransport{RespectRobots: true}
+	clubs, _ := scrape(t, "ffo", srv, transport, nil)
+
+	assertFailed(t, clubs["CHE"])
+	assertAttempts(t, clubs["CHE"], 1)
+	if len(clubs["ARS"].Players) == 0 || len(clubs["LEE"].Players) == 0 {
+		t.Error("got allowed clubs that did not load")
+	}
+	if n := srv.Requests("/text_files/premier/roster/CHE.txt"); n != 0 {
+		t.Errorf("got %d requests for a disallowed roster, want 0", n)
+	}
+	if n := srv.Requests("/robots.txt"); n != 1 {
+		t.Errorf("got %d requests for robots.txt, want 1", n)
+	}
+	if n := srv.Requests(cheInfo); n != 0 {
+		t.Errorf("got %d INFO requests for a failed club, want 0", n)
+	}
+}
+
+func TestCustomCa(t *testing.T) {
+	srv := fixture.NewTLSServer(fixture.SiteFFO)
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := core.GetGame("ffo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrusted, err := core.NewHttpTransport(core.TransportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.NewTeamProvider(srv.TeamsUrl(), core.ProviderOptions{Transport: untrusted}).Load(); err == nil {
+		t.Error("got no error for an untrusted certificate")
+	}
+
+	for name, opts := range map[string]core.TransportOptions{
+		"ca cert":  {CACertFile: caFile},
+		"insecure": {InsecureSkipVerify: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			transport, err := core.NewHttpTransport(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, content := scrape(t, "ffo", srv, transport, nil)
+			assertGolden(t, "ffo", content)
+		})
+	}
+}
+
+func TestProxy(t *testing.T) {
+	srv := fixture.NewServer(fixture.SiteFFO)
+	defer srv.Close()
+
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		req := r.Clone(r.Context())
+		req.RequestURI = ""
+		res, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		for key, values := range res.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(res.StatusCode)
+		io.Copy(w, res.Body)
+	}))
+	defer proxy.Close()
+
+	transport, err := core.NewHttpTransport(core.TransportOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, content := scrape(t, "ffo", srv, transport, nil)
+	assertGolden(t, "ffo", content)
+
+	// 3 clubs pages and 3 files for each of the 3 clubs
+	if n := proxied.Load(); n != 12 {
+		t.Errorf("got %d proxied requests, want 12", n)
+	}
+}
+
+func TestHttpTransportOptions(t *testing.T) {
+	noCerts := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(noCerts, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, opts := range map[string]core.TransportOptions{
+		"proxy without scheme":  {Proxy: "proxy.example.com:8080"},
+		"unsupported proxy":     {Proxy: "ftp://proxy.example.com"},
+		"missing ca file":       {CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"ca file without certs": {CACertFile: noCerts},
+	} {
+		if _, err := core.NewHttpTransport(opts); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
@@ -11,7 +11,8 @@ import (
 )
 
 type FfoWebTeamProvider struct {
-	url string
+	url  string
+	opts core.ProviderOptions
 }
 
 func (p *FfoWebTeamProvider) Load() ([]*core.RosterFile, error) {
@@ -24,9 +25,11 @@ func (p *FfoWebTeamProvider) Load() ([]*core.RosterFile, error) {
 	}
 
 	c := colly.NewCollector(
-		colly.AllowedDomains(rootUrl.Hostname()),
+		colly.AllowedDomains(rootUrl.Host),
 	)
 
+	c.WithTransport(p.opts.HttpTransport())
+
 	c.OnError(func(_ *colly.Response, e error) {
 		err = e
 	})
@@ -58,8 +61,9 @@ func (p *FfoWebTeamProvider) Load() ([]*core.RosterFile, error) {
 	return rosters, err
 }
 
-func NewTeamProvider(url string) *FfoWebTeamProvider {
+func NewTeamProvider(url string, opts core.ProviderOptions) *FfoWebTeamProvider {
 	return &FfoWebTeamProvider{
-		url: url,
+		url:  url,
+		opts: opts,
 	}
 }
@@ -0,0 +1,14 @@
+package ffo
+
+import "player-scraper/internal/core"
+
+func init() {
+	core.RegisterGame(&core.Game{
+		Name:     "ffo",
+		Title:    "FFO",
+		TeamsUrl: "https://www.ffomanager.com/clubs.html",
+		NewTeamProvider: func(url string, opts core.ProviderOptions) core.TeamProvider {
+			return NewTeamProvider(url, opts)
+		},
+	})
+}
@@ -0,0 +1,161 @@
+// Package fixture serves recorded copies of the FFO and SSL websites so the
+// team providers and roster loaders can be tested without network access.
+package fixture
+
+import (
+	"embed"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed sites
+var sites embed.FS
+
+type Site string
+
+const (
+	SiteFFO Site = "ffo"
+	SiteSSL Site = "ssl"
+)
+
+// teamsPages are the paths of the club list of each site.
+var teamsPages = map[Site]string{
+	SiteFFO: "/clubs.html",
+	SiteSSL: "/teams.htm",
+}
+
+type failure struct {
+	status int
+	// times is the number of requests that fail, 0 for all of them
+	times int
+}
+
+// Server is an HTTP test server for one of the recorded sites. Responses
+// can be delayed, failed or replaced per path to simulate a misbehaving
+// website.
+type Server struct {
+	*httptest.Server
+	site      Site
+	files     fs.FS
+	mu        sync.Mutex
+	delays    map[string]time.Duration
+	failures  map[string]*failure
+	overrides map[string][]byte
+	requests  map[string]int
+}
+
+func newServer(site Site) *Server {
+	files, err := fs.Sub(sites, path.Join("sites", string(site)))
+	if err != nil {
+		panic(err)
+	}
+
+	return &Server{
+		site:      site,
+		files:     files,
+		delays:    map[string]time.Duration{},
+		failures:  map[string]*failure{},
+		overrides: map[string][]byte{},
+		requests:  map[string]int{},
+	}
+}
+
+// NewServer starts a server for the site. The caller should call Close when
+// finished to shut it down.
+func NewServer(site Site) *Server {
+	s := newServer(site)
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewTLSServer starts an HTTPS server for the site with a self-signed
+// certificate, see httptest.NewTLSServer.
+func NewTLSServer(site Site) *Server {
+	s := newServer(site)
+	s.Server = httptest.NewTLSServer(s)
+	return s
+}
+
+// TeamsUrl returns the URL of the site's club list.
+func (s *Server) TeamsUrl() string {
+	return s.URL + teamsPages[s.site]
+}
+
+// Delay holds back the responses for the path.
+func (s *Server) Delay(path string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays[path] = d
+}
+
+// Fail answers every request for the path with the status code.
+func (s *Server) Fail(path string, status int) {
+	s.FailTimes(path, status, 0)
+}
+
+// FailTimes answers the first requests for the path with the status code
+// and serves the file afterwards.
+func (s *Server) FailTimes(path string, status int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = &failure{status: status, times: times}
+}
+
+// Replace serves the content for the path instead of the recorded file.
+func (s *Server) Replace(path string, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[path] = []byte(content)
+}
+
+// Requests returns the number of requests made for the path.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean(r.URL.Path)
+
+	s.mu.Lock()
+	s.requests[p]++
+	delay := s.delays[p]
+	fail := s.failures[p]
+	failed := fail != nil && (fail.times == 0 || s.requests[p] <= fail.times)
+	content, replaced := s.overrides[p]
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if failed {
+		http.Error(w, http.StatusText(fail.status), fail.status)
+		return
+	}
+
+	if !replaced {
+		var err error
+		content, err = fs.ReadFile(s.files, strings.TrimPrefix(p, "/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(p)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Write(content)
+}
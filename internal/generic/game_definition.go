@@ -0,0 +1,145 @@
+package generic
+
+import (
+	"fmt"
+	"player-scraper/internal/core"
+	"regexp"
+	"strings"
+)
+
+// Extractor pulls a value out of a club link. From selects the source
+// ("href", "text" or "page" for the URL of the page the link was found on)
+// and Pattern is an optional regex whose first capture group is used.
+type Extractor struct {
+	From    string `yaml:"from" json:"from"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+
+	regex *regexp.Regexp
+}
+
+// GameDefinition describes a game whose rosters are plain text files linked
+// from a clubs page. URL templates may use the {code}, {league}, {name} and
+// {href} placeholders and are relative to the teams URL host unless absolute.
+type GameDefinition struct {
+	Name             string    `yaml:"name" json:"name"`
+	Title            string    `yaml:"title" json:"title"`
+	TeamsUrl         string    `yaml:"teamsUrl" json:"teamsUrl"`
+	ClubLinkSelector string    `yaml:"clubLinkSelector" json:"clubLinkSelector"`
+	FollowSelector   string    `yaml:"followSelector" json:"followSelector"`
+	Code             Extractor `yaml:"code" json:"code"`
+	League           Extractor `yaml:"league" json:"league"`
+	ClubName         Extractor `yaml:"clubName" json:"clubName"`
+	RosterUrl        string    `yaml:"rosterUrl" json:"rosterUrl"`
+	InfoUrl          string    `yaml:"infoUrl" json:"infoUrl"`
+	AcademyUrl       string    `yaml:"academyUrl" json:"academyUrl"`
+}
+
+type gamesFile struct {
+	Games []*GameDefinition `yaml:"games" json:"games"`
+}
+
+func (e *Extractor) compile() error {
+	switch e.From {
+	case "":
+		e.From = "href"
+	case "href", "text", "page":
+	default:
+		return fmt.Errorf("unknown extractor source %q", e.From)
+	}
+
+	if e.Pattern != "" {
+		regex, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return err
+		}
+		e.regex = regex
+	}
+	return nil
+}
+
+// Extract returns the value for the given link attributes, or an empty
+// string if the pattern doesn't match.
+func (e *Extractor) Extract(href string, text string, page string) string {
+	value := href
+	switch e.From {
+	case "text":
+		value = text
+	case "page":
+		value = page
+	}
+
+	value = strings.TrimSpace(value)
+	if e.regex == nil {
+		return value
+	}
+
+	match := e.regex.FindStringSubmatch(value)
+	if match == nil {
+		return ""
+	} else if len(match) > 1 {
+		return match[1]
+	}
+	return match[0]
+}
+
+func (d *GameDefinition) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("game definition is missing a name")
+	}
+	if d.TeamsUrl == "" || d.ClubLinkSelector == "" || d.RosterUrl == "" {
+		return fmt.Errorf("game %s: teamsUrl, clubLinkSelector and rosterUrl are required", d.Name)
+	}
+	if d.Title == "" {
+		d.Title = strings.ToUpper(d.Name)
+	}
+
+	for _, e := range []*Extractor{&d.Code, &d.League, &d.ClubName} {
+		if err := e.compile(); err != nil {
+			return fmt.Errorf("game %s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// LoadGameDefinitions reads game definitions from a YAML or JSON file.
+func LoadGameDefinitions(path string) ([]*GameDefinition, error) {
+	file := gamesFile{}
+	if err := core.ReadConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	for _, d := range file.Games {
+		if err := d.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return file.Games, nil
+}
+
+// RegisterGames loads the definitions in the file and registers each one
+// as a game.
+func RegisterGames(path string) error {
+	defs, err := LoadGameDefinitions(path)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range defs {
+		if _, err := core.GetGame(d.Name); err == nil {
+			return fmt.Errorf("game already registered: %s", d.Name)
+		}
+
+		def := d
+		core.RegisterGame(&core.Game{
+			Name:     def.Name,
+			Title:    def.Title,
+			TeamsUrl: def.TeamsUrl,
+			NewTeamProvider: func(url string, opts core.ProviderOptions) core.TeamProvider {
+				return NewTeamProvider(url, def, opts)
+			},
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package generic
+
+import (
+	"net/url"
+	"player-scraper/internal/core"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+type GenericWebTeamProvider struct {
+	url        string
+	definition *GameDefinition
+	opts       core.ProviderOptions
+}
+
+func expandTemplate(template string, roster *core.RosterFile, href string) string {
+	return strings.NewReplacer(
+		"{code}", roster.Code,
+		"{league}", roster.League,
+		"{name}", roster.Name,
+		"{href}", strings.TrimPrefix(href, "/"),
+	).Replace(template)
+}
+
+func (p *GenericWebTeamProvider) Load() ([]*core.RosterFile, error) {
+	rosters := []*core.RosterFile{}
+	var err error
+
+	rootUrl, err := url.Parse(p.url)
+	if err != nil {
+		return rosters, err
+	}
+
+	c := colly.NewCollector(
+		colly.AllowedDomains(rootUrl.Host),
+		colly.MaxDepth(2),
+	)
+
+	c.WithTransport(p.opts.HttpTransport())
+
+	c.OnError(func(_ *colly.Response, e error) {
+		err = e
+	})
+
+	seen := map[string]bool{}
+	c.OnHTML(p.definition.ClubLinkSelector, func(e *colly.HTMLElement) {
+		u, err := url.Parse(e.Request.AbsoluteURL(e.Attr("href")))
+		if err != nil {
+			return
+		}
+
+		href := u.Path
+		if u.Host != rootUrl.Host {
+			href = u.String()
+		}
+		page := e.Request.URL.Path
+		roster := &core.RosterFile{
+			Code:   p.definition.Code.Extract(href, e.Text, page),
+			League: p.definition.League.Extract(href, e.Text, page),
+			Name:   p.definition.ClubName.Extract(href, e.Text, page),
+		}
+		if roster.Code == "" || seen[roster.Code] {
+			return
+		}
+		seen[roster.Code] = true
+
+		roster.FileLocation = expandTemplate(p.definition.RosterUrl, roster, href)
+		if p.definition.InfoUrl != "" {
+			roster.InfoFileLocation = expandTemplate(p.definition.InfoUrl, roster, href)
+		}
+		if p.definition.AcademyUrl != "" {
+			roster.AcademyFileLocation = expandTemplate(p.definition.AcademyUrl, roster, href)
+		}
+		rosters = append(rosters, roster)
+	})
+
+	if p.definition.FollowSelector != "" {
+		c.OnHTML(p.definition.FollowSelector, func(e *colly.HTMLElement) {
+			e.Request.Visit(e.Attr("href"))
+		})
+	}
+
+	c.Visit(rootUrl.String())
+	c.Wait()
+
+	return rosters, err
+}
+
+func NewTeamProvider(url string, definition *GameDefinition, opts core.ProviderOptions) *GenericWebTeamProvider {
+	return &GenericWebTeamProvider{
+		url:        url,
+		definition: definition,
+		opts:       opts,
+	}
+}
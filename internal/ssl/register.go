@@ -0,0 +1,14 @@
+package ssl
+
+import "player-scraper/internal/core"
+
+func init() {
+	core.RegisterGame(&core.Game{
+		Name:     "ssl",
+		Title:    "SSL",
+		TeamsUrl: "http://www.ssl2001.ukhome.net/teams.htm",
+		NewTeamProvider: func(url string, opts core.ProviderOptions) core.TeamProvider {
+			return NewTeamProvider(url, opts)
+		},
+	})
+}
@@ -11,7 +11,8 @@ import (
 )
 
 type SslWebTeamProvider struct {
-	url string
+	url  string
+	opts core.ProviderOptions
 }
 
 func (p *SslWebTeamProvider) Load() ([]*core.RosterFile, error) {
@@ -24,9 +25,11 @@ func (p *SslWebTeamProvider) Load() ([]*core.RosterFile, error) {
 	}
 
 	c := colly.NewCollector(
-		colly.AllowedDomains(url.Hostname()),
+		colly.AllowedDomains(url.Host),
 	)
 
+	c.WithTransport(p.opts.HttpTransport())
+
 	c.OnError(func(_ *colly.Response, e error) {
 		err = e
 	})
@@ -46,6 +49,7 @@ func (p *SslWebTeamProvider) Load() ([]*core.RosterFile, error) {
 					Name:         strings.TrimSpace(fmt.Sprintf("%s Youth", fields.Eq(0).Text())),
 					Code:         strings.TrimSpace(fields.Eq(4).Text()),
 					League:       strings.TrimSpace(fmt.Sprintf("Youth %s", fields.Eq(3).Text())),
+					Squad:        core.SquadYouth,
 					FileLocation: fields.Eq(4).Find("a").AttrOr("href", fmt.Sprintf("%s.txt", fields.Eq(4).Text())),
 				})
 			}
@@ -58,8 +62,9 @@ func (p *SslWebTeamProvider) Load() ([]*core.RosterFile, error) {
 	return rosters, err
 }
 
-func NewTeamProvider(url string) *SslWebTeamProvider {
+func NewTeamProvider(url string, opts core.ProviderOptions) *SslWebTeamProvider {
 	return &SslWebTeamProvider{
-		url: url,
+		url:  url,
+		opts: opts,
 	}
 }
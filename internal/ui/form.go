@@ -157,7 +157,7 @@ func (f FormModel) View() string {
 func (f FormModel) IsComplete() bool {
 	complete := true
 	for _, in := range f.inputs {
-		if in.Error() != nil || in.Value() == "" {
+		if in.Error() != nil || (in.Value() == "" && !in.optional) {
 			complete = false
 			break
 		}
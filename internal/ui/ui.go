@@ -153,6 +153,32 @@ func validateBool(v string) error {
 	return nil
 }
 
+func validateSquads(v string) error {
+	_, err := core.ParseSquads(splitList(v))
+	return err
+}
+
+func validateFormat(v string) error {
+	_, err := core.ParseExportFormat(v)
+	return err
+}
+
+// splitList splits a comma separated form value, "all" and "none" mean an
+// empty list.
+func splitList(v string) []string {
+	if strings.EqualFold(v, "all") || strings.EqualFold(v, "none") {
+		return nil
+	}
+
+	values := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
 func getInputsForMode(mode ScrapeMode) []FormInputModel {
 	var cwd, err = os.Getwd()
 	if err != nil {
@@ -162,6 +188,12 @@ func getInputsForMode(mode ScrapeMode) []FormInputModel {
 	inputs := []FormInputModel{
 		{id: "outputDir", field: createInputModel("Report output dir: ", cwd, 255, validatePathDir)},
 		{id: "excelExport", field: createInputModel("Use Excel formulas: ", "y", 1, validateBool)},
+		{id: "format", field: createInputModel("Export format: ", string(core.FormatCsv), 6, validateFormat)},
+		{id: "columns", optional: true, field: createInputModel("Columns (comma separated): ", "all", 255, nil)},
+		{id: "sort", optional: true, field: createInputModel("Sort by (e.g. League,-Sh): ", "none", 255, nil)},
+		{id: "excludeAcademy", field: createInputModel("Exclude academy players: ", "n", 1, validateBool)},
+		{id: "leagues", optional: true, field: createInputModel("Leagues (comma separated): ", "all", 255, nil)},
+		{id: "squads", optional: true, field: createInputModel("Squads (Senior, Academy, Youth): ", "all", 255, validateSquads)},
 	}
 
 	if mode != ScrapeOnly {
@@ -220,12 +252,20 @@ func Run(appName string) (core.ScraperOptions, bool) {
 		return value
 	}
 
+	format, _ := core.ParseExportFormat(getFormValue("format"))
+	squads, _ := core.ParseSquads(splitList(getFormValue("squads")))
 	opts := core.ScraperOptions{
-		LocalOnly:     mo.mode == ScrapeOnlyLocal,
-		DownloadFiles: mo.mode == ScrapeAndDownload,
-		RosterDir:     "",
-		OutputDir:     getFormValue("outputDir"),
-		ExcelExport:   strings.ToLower(getFormValue("excelExport")) == "y",
+		LocalOnly:      mo.mode == ScrapeOnlyLocal,
+		DownloadFiles:  mo.mode == ScrapeAndDownload,
+		RosterDir:      "",
+		OutputDir:      getFormValue("outputDir"),
+		ExcelExport:    strings.ToLower(getFormValue("excelExport")) == "y",
+		Format:         format,
+		Columns:        splitList(getFormValue("columns")),
+		Sort:           core.ParseSortKeys(strings.Join(splitList(getFormValue("sort")), ",")),
+		ExcludeAcademy: strings.ToLower(getFormValue("excludeAcademy")) == "y",
+		Leagues:        splitList(getFormValue("leagues")),
+		Squads:         squads,
 	}
 
 	if mo.mode != ScrapeOnly {